@@ -0,0 +1,592 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// GenerateFakeFile generates the in-memory fake of the database into Config.OutFakeFilePath.
+func (m *Maker) GenerateFakeFile() error {
+	f, err := os.Create(m.config.OutFakeFilePath)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to open %q: %w", m.config.OutFakeFilePath, err)
+	}
+	defer f.Close()
+
+	if err := m.GenerateFake(f); err != nil {
+		return fmt.Errorf("myddlmaker: failed to generate fake file: %w", err)
+	}
+
+	return f.Close()
+}
+
+// GenerateFake generates the in-memory fake of the database for unit tests.
+// The FakeDB type has the same methods as the Queries type for the basic CRUD functions,
+// and it is built only with the build tag Config.FakeTag.
+// See the doc comment of the generated FakeDB for the functions that have no fakes.
+func (m *Maker) GenerateFake(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := m.parse(); err != nil {
+		return err
+	}
+
+	if err := m.initGoImports(); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	m.generateFakeDB(&body)
+	for _, table := range m.tables {
+		m.generateFakeTable(&body, table)
+	}
+
+	m.generateFakeHeader(&buf)
+	buf.Write(body.Bytes())
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+func (m *Maker) generateFakeHeader(w io.Writer) {
+	io.WriteString(w, "// Code generated by https://github.com/shogo82148/myddlmaker; DO NOT EDIT.\n\n")
+	fmt.Fprintf(w, "//go:build !%s && %s\n\n", m.config.Tag, m.config.FakeTag)
+	fmt.Fprintf(w, "package %s\n\n", m.config.PackageName)
+	m.imports.add("context", "context")
+	m.imports.add("database/sql", "sql")
+	fmt.Fprintf(w, "import (\n")
+	for _, spec := range m.imports.specs() {
+		fmt.Fprintf(w, "%s\n", spec)
+	}
+	fmt.Fprintf(w, ")\n\n")
+}
+
+func (m *Maker) generateFakeDB(w io.Writer) {
+	m.imports.add("errors", "errors")
+	m.imports.add("fmt", "fmt")
+	m.imports.add("sync", "sync")
+
+	fmt.Fprintf(w, "// FakeDB is an in-memory fake of the database for unit tests.\n")
+	fmt.Fprintf(w, "// Its methods have the same signatures as the methods of Queries.\n")
+	fmt.Fprintf(w, "//\n")
+	fmt.Fprintf(w, "// It has the fakes of InsertX, SelectX, SelectXByPrimaryKeys, the lookups by the unique indexes, SelectAllX,\n")
+	fmt.Fprintf(w, "// UpdateX, DeleteX, DeleteXRowsAffected and HardDeleteX.\n")
+	fmt.Fprintf(w, "// It doesn't have the fakes of SelectXMapByPrimaryKeys, the IncludingDeleted variants, IterAllX, IterAllXBatched,\n")
+	fmt.Fprintf(w, "// ListXBy..., the foreign key loaders, UpdateXColumns, UpdateXDiff, UpsertX, UpsertXColumns\n")
+	fmt.Fprintf(w, "// and the locking reads SelectXWithLock.\n")
+	fmt.Fprintf(w, "//\n")
+	fmt.Fprintf(w, "// The text columns with the case-insensitive collations are compared case-insensitively,\n")
+	fmt.Fprintf(w, "// but the other rules of the collations are not emulated.\n")
+	fmt.Fprintf(w, "//\n")
+	fmt.Fprintf(w, "// It is safe for concurrent use by multiple goroutines.\n")
+	fmt.Fprintf(w, "type FakeDB struct {\n")
+	fmt.Fprintf(w, "mu sync.Mutex\n")
+	for _, table := range m.tables {
+		fmt.Fprintf(w, "table%[1]s fake%[1]sTable\n", table.rawName)
+	}
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(w, `// NewFakeDB returns a new empty FakeDB.
+func NewFakeDB() *FakeDB {
+	return &FakeDB{}
+}
+
+// ErrFakeDuplicateEntry is returned by FakeDB if a row violates the primary key or a unique index.
+var ErrFakeDuplicateEntry = errors.New("%s: duplicate entry")
+
+`, m.config.PackageName)
+}
+
+func (m *Maker) generateFakeTable(w io.Writer, table *table) {
+	var auto *column
+	for _, c := range table.columns {
+		if c.autoIncr {
+			auto = c
+		}
+	}
+
+	fmt.Fprintf(w, "type fake%[1]sTable struct {\n", table.rawName)
+	fmt.Fprintf(w, "// rows maps the primary keys to the rows.\n")
+	fmt.Fprintf(w, "rows map[fake%[1]sKey]*fake%[1]sRow\n", table.rawName)
+	fmt.Fprintf(w, "seq int\n")
+	if auto != nil {
+		fmt.Fprintf(w, "autoIncrement int64\n")
+	}
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(w, "type fake%[1]sRow struct {\n", table.rawName)
+	fmt.Fprintf(w, "v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "// seq is the order of the insertion.\n")
+	fmt.Fprintf(w, "seq int\n")
+	fmt.Fprintf(w, "}\n\n")
+
+	m.generateFakeTableKey(w, table)
+	m.generateFakeTableHelpers(w, table)
+	m.generateFakeTableInsert(w, table, auto)
+	m.generateFakeTableSelect(w, table)
+	m.generateFakeTableUpdate(w, table)
+	m.generateFakeTableDelete(w, table)
+}
+
+// generateFakeTableKey generates the comparable type of the primary key of table,
+// and the function that returns the primary key of a row.
+func (m *Maker) generateFakeTableKey(w io.Writer, table *table) {
+	var fields, values, nullConds []string
+	for _, key := range table.primaryKey.columns {
+		for _, c := range table.columns {
+			if c.name != key {
+				continue
+			}
+			typ, value, nullCond := goValue(c, "v")
+			typeName, value := m.goFakeValue(c, typ, value)
+			fields = append(fields, c.rawName+" "+typeName)
+			values = append(values, c.rawName+": "+value)
+			if nullCond != "" {
+				nullConds = append(nullConds, nullCond)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "// fake%sKey is the primary key of %s.\n", table.rawName, quote(table.name))
+	fmt.Fprintf(w, "type fake%sKey struct {\n", table.rawName)
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s\n", field)
+	}
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(w, "// newFake%[1]sKey returns the primary key of v.\n", table.rawName)
+	fmt.Fprintf(w, "// It returns false if the primary key has NULL.\n")
+	fmt.Fprintf(w, "func newFake%[1]sKey(v *%[2]s) (fake%[1]sKey, bool) {\n", table.rawName, m.goTableType(table))
+	if len(nullConds) > 0 {
+		fmt.Fprintf(w, "if %s {\n return fake%sKey{}, false \n}\n", strings.Join(nullConds, " || "), table.rawName)
+	}
+	fmt.Fprintf(w, "return fake%sKey{%s}, true\n", table.rawName, strings.Join(values, ", "))
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// goFakeValue returns the name of the type and the Go expression of expr of typ in the column c,
+// that are normalized to be compared by ==.
+// The times are converted to UTC and truncated to the precision of the column,
+// the strings of the case-insensitive columns are converted to lower case,
+// and the byte slices are converted to strings.
+func (m *Maker) goFakeValue(c *column, typ reflect.Type, expr string) (string, string) {
+	switch {
+	case typ.Kind() == reflect.String && m.isCaseInsensitive(c):
+		m.imports.add("strings", "strings")
+		if typ.PkgPath() == "" {
+			return "string", fmt.Sprintf("strings.ToLower(%s)", expr)
+		}
+		return m.imports.typeName(typ), fmt.Sprintf("%s(strings.ToLower(string(%s)))", m.imports.typeName(typ), expr)
+	case typ == timeType:
+		return m.imports.typeName(typ), fmt.Sprintf("%s.UTC().Truncate(%s)", expr, goFractionalSeconds(c.size))
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		return "string", fmt.Sprintf("string(%s)", expr)
+	case typ.Comparable():
+		return m.imports.typeName(typ), expr
+	}
+	return "string", fmt.Sprintf("fmt.Sprintf(\"%%#v\", %s)", expr)
+}
+
+// isCaseInsensitive reports whether MySQL compares the values of the text column c case-insensitively.
+// The collation is the one of the column or the default of the tables.
+// If no collation is set, the default collation of the character set is used,
+// which is case-insensitive except the binary character set.
+func (m *Maker) isCaseInsensitive(c *column) bool {
+	switch strings.ToUpper(c.typ) {
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET":
+	default:
+		return false
+	}
+	collate := c.collate
+	if collate == "" && c.charset == "" {
+		collate = m.config.DB.Collate
+	}
+	if collate == "" {
+		return cmp.Or(c.charset, m.config.DB.Charset) != "binary"
+	}
+	return strings.HasSuffix(collate, "_ci")
+}
+
+// goFakeDeleted returns the Go expression that reports whether the row is soft-deleted.
+// It returns "" if table has no soft delete column.
+func goFakeDeleted(table *table, row string) string {
+	col := table.softDeleteColumn()
+	if col == nil {
+		return ""
+	}
+	_, _, nullCond := goValue(col, row+".v")
+	return goNotNull(nullCond)
+}
+
+// goNotNull returns the negation of the condition nullCond returned by goValue.
+func goNotNull(nullCond string) string {
+	if s, ok := strings.CutSuffix(nullCond, " == nil"); ok {
+		return s + " != nil"
+	}
+	return strings.TrimPrefix(nullCond, "!")
+}
+
+func (m *Maker) generateFakeTableHelpers(w io.Writer, table *table) {
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		columns[c.name] = c
+	}
+
+	// insert
+	fmt.Fprintf(w, "func (t *fake%[1]sTable) insert(v *%[2]s) error {\n", table.rawName, m.goTableType(table))
+	fmt.Fprintf(w, "key, ok := newFake%sKey(v)\n", table.rawName)
+	fmt.Fprintf(w, "if !ok {\n")
+	fmt.Fprintf(w, "return errors.New(%q)\n", fmt.Sprintf("%s: the primary key of %s can't be NULL", m.config.PackageName, quote(table.name)))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if _, ok := t.rows[key]; ok {\n")
+	fmt.Fprintf(w, "return fmt.Errorf(\"%%w for key %%q\", ErrFakeDuplicateEntry, %q)\n", table.name+".PRIMARY")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if err := t.checkUnique(v, key); err != nil {\n return err \n}\n")
+	fmt.Fprintf(w, "if t.rows == nil {\n")
+	fmt.Fprintf(w, "t.rows = map[fake%[1]sKey]*fake%[1]sRow{}\n", table.rawName)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "t.seq++\n")
+	fmt.Fprintf(w, "t.rows[key] = &fake%sRow{v: *v, seq: t.seq}\n", table.rawName)
+	fmt.Fprintf(w, "return nil\n")
+	fmt.Fprintf(w, "}\n\n")
+
+	// checkUnique
+	// NULL values don't violate the unique indexes.
+	fmt.Fprintf(w, "// checkUnique returns an error if v violates the unique indexes.\n")
+	fmt.Fprintf(w, "// The row of the primary key key is ignored.\n")
+	fmt.Fprintf(w, "func (t *fake%[1]sTable) checkUnique(v *%[2]s, key fake%[1]sKey) error {\n", table.rawName, m.goTableType(table))
+	if len(table.uniqueIndexes) == 0 {
+		fmt.Fprintf(w, "return nil\n")
+		fmt.Fprintf(w, "}\n\n")
+	} else {
+		fmt.Fprintf(w, "for k, row := range t.rows {\n")
+		fmt.Fprintf(w, "if k == key {\n continue \n}\n")
+		for _, idx := range table.uniqueIndexes {
+			// the NULL checks come first, so the pointers are not dereferenced if they are nil.
+			var conditions, equals []string
+			for _, name := range idx.columns {
+				c := columns[name]
+				typ, value, nullCond := goValue(c, "v")
+				_, rowValue, rowNullCond := goValue(c, "row.v")
+				if nullCond != "" {
+					conditions = append(conditions, goNotNull(nullCond), goNotNull(rowNullCond))
+				}
+				_, value = m.goFakeValue(c, typ, value)
+				_, rowValue = m.goFakeValue(c, typ, rowValue)
+				equals = append(equals, rowValue+" == "+value)
+			}
+			conditions = append(conditions, equals...)
+			fmt.Fprintf(w, "if %s {\n", strings.Join(conditions, " && "))
+			fmt.Fprintf(w, "return fmt.Errorf(\"%%w for key %%q\", ErrFakeDuplicateEntry, %q)\n", table.name+"."+idx.name)
+			fmt.Fprintf(w, "}\n")
+		}
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "return nil\n")
+		fmt.Fprintf(w, "}\n\n")
+	}
+
+	// all
+	// the rows are sorted by the primary key if it can be compared by cmp.Compare,
+	// otherwise they are in the order of the insertion.
+	m.imports.add("slices", "slices")
+	fmt.Fprintf(w, "// all returns the rows that are not deleted in the order of the primary key.\n")
+	fmt.Fprintf(w, "func (t *fake%[1]sTable) all() []*fake%[1]sRow {\n", table.rawName)
+	fmt.Fprintf(w, "rows := make([]*fake%sRow, 0, len(t.rows))\n", table.rawName)
+	fmt.Fprintf(w, "for _, row := range t.rows {\n")
+	if deleted := goFakeDeleted(table, "row"); deleted != "" {
+		fmt.Fprintf(w, "if %s {\n continue \n}\n", deleted)
+	}
+	fmt.Fprintf(w, "rows = append(rows, row)\n")
+	fmt.Fprintf(w, "}\n")
+	var compares []string
+	for _, key := range table.primaryKey.columns {
+		c := columns[key]
+		typ, a, _ := goValue(c, "a.v")
+		_, b, _ := goValue(c, "b.v")
+		if !isOrderedKind(typ.Kind()) {
+			compares = nil
+			break
+		}
+		compares = append(compares, fmt.Sprintf("cmp.Compare(%s, %s)", a, b))
+	}
+	if len(compares) == 0 {
+		compares = []string{"cmp.Compare(a.seq, b.seq)"}
+	}
+	m.imports.add("cmp", "cmp")
+	fmt.Fprintf(w, "slices.SortFunc(rows, func(a, b *fake%sRow) int {\n", table.rawName)
+	fmt.Fprintf(w, "return cmp.Or(%s)\n", strings.Join(compares, ", "))
+	fmt.Fprintf(w, "})\n")
+	fmt.Fprintf(w, "return rows\n")
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// isOrderedKind reports whether the values of k can be compared by cmp.Compare.
+func isOrderedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return isIntegerKind(k)
+}
+
+func (m *Maker) generateFakeTableInsert(w io.Writer, table *table, auto *column) {
+	created, updated := table.createdColumn(), table.updatedColumn()
+
+	fmt.Fprintf(w, "// Insert%[1]s is the fake of Insert%[1]s.\n", table.rawName)
+	fmt.Fprintf(w, "// The values are inserted all or nothing, like a multi-row INSERT statement.\n")
+	fmt.Fprintf(w, "func (db *FakeDB) Insert%[1]s(ctx context.Context, values ...*%[2]s) error {\n", table.rawName, m.goTableType(table))
+	fmt.Fprintf(w, "db.mu.Lock()\n")
+	fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+	fmt.Fprintf(w, "t := &db.table%s\n", table.rawName)
+	if created != nil || updated != nil {
+		fmt.Fprintf(w, "now := Now()\n")
+	}
+	if auto != nil {
+		fmt.Fprintf(w, "autoIncrement := t.autoIncrement\n")
+	}
+	fmt.Fprintf(w, "rows := make([]%s, 0, len(values))\n", m.goTableType(table))
+	fmt.Fprintf(w, "for _, value := range values {\n")
+	m.generateGoSetTimestamps(w, "value", created, updated)
+	fmt.Fprintf(w, "v := *value\n")
+	if auto != nil {
+		// assign the AUTO_INCREMENT value if the field is zero,
+		// otherwise the counter follows the explicit value.
+		typ := m.imports.typeName(auto.rawType)
+		if auto.fieldType.Kind() == reflect.Pointer {
+			fmt.Fprintf(w, "if v.%[1]s == nil || *v.%[1]s == 0 {\n", auto.rawName)
+			fmt.Fprintf(w, "autoIncrement++\n")
+			fmt.Fprintf(w, "id := %s(autoIncrement)\n", typ)
+			fmt.Fprintf(w, "v.%s = &id\n", auto.rawName)
+			fmt.Fprintf(w, "} else if int64(*v.%s) > autoIncrement {\n", auto.rawName)
+			fmt.Fprintf(w, "autoIncrement = int64(*v.%s)\n", auto.rawName)
+			fmt.Fprintf(w, "}\n")
+		} else {
+			fmt.Fprintf(w, "if v.%s == 0 {\n", auto.rawName)
+			fmt.Fprintf(w, "autoIncrement++\n")
+			fmt.Fprintf(w, "v.%s = %s(autoIncrement)\n", auto.rawName, typ)
+			fmt.Fprintf(w, "} else if int64(v.%s) > autoIncrement {\n", auto.rawName)
+			fmt.Fprintf(w, "autoIncrement = int64(v.%s)\n", auto.rawName)
+			fmt.Fprintf(w, "}\n")
+		}
+	}
+	fmt.Fprintf(w, "rows = append(rows, v)\n")
+	fmt.Fprintf(w, "}\n")
+
+	// remove the inserted rows if any of them fails.
+	fmt.Fprintf(w, "for i := range rows {\n")
+	fmt.Fprintf(w, "if err := t.insert(&rows[i]); err != nil {\n")
+	fmt.Fprintf(w, "for j := range rows[:i] {\n")
+	fmt.Fprintf(w, "key, _ := newFake%sKey(&rows[j])\n", table.rawName)
+	fmt.Fprintf(w, "delete(t.rows, key)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return err\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "}\n")
+	if auto != nil {
+		fmt.Fprintf(w, "t.autoIncrement = autoIncrement\n")
+		fmt.Fprintf(w, "for i, v := range rows {\n")
+		fmt.Fprintf(w, "values[i].%[1]s = v.%[1]s\n", auto.rawName)
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "return nil\n")
+	fmt.Fprintf(w, "}\n\n")
+}
+
+func (m *Maker) generateFakeTableSelect(w io.Writer, table *table) {
+	deleted := goFakeDeleted(table, "row")
+	notFound := "!ok"
+	if deleted != "" {
+		notFound = "!ok || " + deleted
+	}
+
+	// SelectX
+	fmt.Fprintf(w, "// Select%[1]s is the fake of Select%[1]s.\n", table.rawName)
+	fmt.Fprintf(w, "func (db *FakeDB) Select%[1]s(ctx context.Context, primaryKeys *%[2]s) (*%[2]s, error) {\n", table.rawName, m.goTableType(table))
+	fmt.Fprintf(w, "db.mu.Lock()\n")
+	fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+	fmt.Fprintf(w, "key, ok := newFake%sKey(primaryKeys)\n", table.rawName)
+	fmt.Fprintf(w, "if !ok {\n return nil, sql.ErrNoRows \n}\n")
+	fmt.Fprintf(w, "row, ok := db.table%s.rows[key]\n", table.rawName)
+	fmt.Fprintf(w, "if %s {\n return nil, sql.ErrNoRows \n}\n", notFound)
+	fmt.Fprintf(w, "v := row.v\n")
+	fmt.Fprintf(w, "return &v, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+
+	// SelectXByPrimaryKeys
+	fmt.Fprintf(w, "// Select%[1]sByPrimaryKeys is the fake of Select%[1]sByPrimaryKeys.\n", table.rawName)
+	fmt.Fprintf(w, "func (db *FakeDB) Select%[1]sByPrimaryKeys(ctx context.Context, keys ...*%[2]s) ([]*%[2]s, error) {\n", table.rawName, m.goTableType(table))
+	fmt.Fprintf(w, "db.mu.Lock()\n")
+	fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+	fmt.Fprintf(w, "var ret []*%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "found := map[fake%sKey]bool{}\n", table.rawName)
+	fmt.Fprintf(w, "for _, key := range keys {\n")
+	fmt.Fprintf(w, "k, ok := newFake%sKey(key)\n", table.rawName)
+	fmt.Fprintf(w, "if !ok {\n continue \n}\n")
+	fmt.Fprintf(w, "row, ok := db.table%s.rows[k]\n", table.rawName)
+	fmt.Fprintf(w, "if %s || found[k] {\n continue \n}\n", notFound)
+	fmt.Fprintf(w, "found[k] = true\n")
+	fmt.Fprintf(w, "v := row.v\n")
+	fmt.Fprintf(w, "ret = append(ret, &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return ret, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+
+	// SelectXByIdx
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		columns[c.name] = c
+	}
+	for _, idx := range table.uniqueIndexes {
+		funcName := "Select" + table.rawName + "By" + snakeToCamel(idx.name)
+		params := make([]string, 0, len(idx.columns))
+		var conditions, equals []string
+		for _, name := range idx.columns {
+			c := columns[name]
+			arg := goParamName(c.rawName)
+			params = append(params, arg+" "+m.imports.typeName(c.fieldType))
+
+			// NULL never matches any value.
+			// the NULL checks come first, so the pointers are not dereferenced if they are nil.
+			typ, value, nullCond := goExprValue(c.fieldType, arg)
+			_, rowValue, rowNullCond := goValue(c, "row.v")
+			if nullCond != "" {
+				conditions = append(conditions, goNotNull(nullCond), goNotNull(rowNullCond))
+			}
+			_, value = m.goFakeValue(c, typ, value)
+			_, rowValue = m.goFakeValue(c, typ, rowValue)
+			equals = append(equals, rowValue+" == "+value)
+		}
+		conditions = append(conditions, equals...)
+
+		fmt.Fprintf(w, "// %[1]s is the fake of %[1]s.\n", funcName)
+		fmt.Fprintf(w, "func (db *FakeDB) %s(ctx context.Context, %s) (*%s, error) {\n", funcName, strings.Join(params, ", "), m.goTableType(table))
+		fmt.Fprintf(w, "db.mu.Lock()\n")
+		fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+		fmt.Fprintf(w, "for _, row := range db.table%s.all() {\n", table.rawName)
+		fmt.Fprintf(w, "if %s {\n", strings.Join(conditions, " && "))
+		fmt.Fprintf(w, "v := row.v\n")
+		fmt.Fprintf(w, "return &v, nil\n")
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "return nil, sql.ErrNoRows\n")
+		fmt.Fprintf(w, "}\n\n")
+	}
+
+	// SelectAllX
+	fmt.Fprintf(w, "// SelectAll%[1]s is the fake of SelectAll%[1]s.\n", table.rawName)
+	fmt.Fprintf(w, "func (db *FakeDB) SelectAll%[1]s(ctx context.Context) ([]*%[2]s, error) {\n", table.rawName, m.goTableType(table))
+	fmt.Fprintf(w, "db.mu.Lock()\n")
+	fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+	fmt.Fprintf(w, "var ret []*%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "for _, row := range db.table%s.all() {\n", table.rawName)
+	fmt.Fprintf(w, "v := row.v\n")
+	fmt.Fprintf(w, "ret = append(ret, &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return ret, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+}
+
+func (m *Maker) generateFakeTableUpdate(w io.Writer, table *table) {
+	version := table.versionColumn()
+	created, updated := table.createdColumn(), table.updatedColumn()
+
+	fmt.Fprintf(w, "// Update%[1]s is the fake of Update%[1]s.\n", table.rawName)
+	fmt.Fprintf(w, "func (db *FakeDB) Update%[1]s(ctx context.Context, values ...*%[2]s) error {\n", table.rawName, m.goTableType(table))
+	fmt.Fprintf(w, "db.mu.Lock()\n")
+	fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+	fmt.Fprintf(w, "t := &db.table%s\n", table.rawName)
+	if updated != nil {
+		fmt.Fprintf(w, "now := Now()\n")
+	}
+	fmt.Fprintf(w, "for _, value := range values {\n")
+	m.generateGoSetTimestamps(w, "value", updated)
+	fmt.Fprintf(w, "key, ok := newFake%sKey(value)\n", table.rawName)
+	fmt.Fprintf(w, "row := t.rows[key]\n")
+	fmt.Fprintf(w, "ok = ok && row != nil\n")
+	if version == nil {
+		fmt.Fprintf(w, "if !ok {\n continue \n}\n")
+	} else {
+		keys := make([]string, 0, len(table.primaryKey.columns))
+		for _, key := range table.primaryKey.columns {
+			for _, c := range table.columns {
+				if c.name == key {
+					keys = append(keys, "value."+c.rawName)
+				}
+			}
+		}
+		fmt.Fprintf(w, "if !ok || row.v.%[1]s != value.%[1]s {\n", version.rawName)
+		fmt.Fprintf(w, "return &ErrStaleObject{Table: %q, PrimaryKey: []any{%s}}\n", table.name, strings.Join(keys, ", "))
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "if err := t.checkUnique(value, key); err != nil {\n return err \n}\n")
+	fmt.Fprintf(w, "v := *value\n")
+	if created != nil {
+		fmt.Fprintf(w, "v.%[1]s = row.v.%[1]s\n", created.rawName)
+	}
+	if version != nil {
+		fmt.Fprintf(w, "v.%s++\n", version.rawName)
+		fmt.Fprintf(w, "value.%s++\n", version.rawName)
+	}
+	fmt.Fprintf(w, "row.v = v\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return nil\n")
+	fmt.Fprintf(w, "}\n\n")
+}
+
+func (m *Maker) generateFakeTableDelete(w io.Writer, table *table) {
+	generate := func(funcName string, softDelete *column) {
+		fmt.Fprintf(w, "// %[1]s%[2]s is the fake of %[1]s%[2]s.\n", funcName, table.rawName)
+		fmt.Fprintf(w, "func (db *FakeDB) %[2]s%[1]s(ctx context.Context, values ...*%[3]s) error {\n", table.rawName, funcName, m.goTableType(table))
+		fmt.Fprintf(w, "_, err := db.%[2]s%[1]sRowsAffected(ctx, values...)\n", table.rawName, funcName)
+		fmt.Fprintf(w, "return err\n")
+		fmt.Fprintf(w, "}\n\n")
+
+		fmt.Fprintf(w, "// %[1]s%[2]sRowsAffected is the fake of %[1]s%[2]sRowsAffected.\n", funcName, table.rawName)
+		fmt.Fprintf(w, "func (db *FakeDB) %[2]s%[1]sRowsAffected(ctx context.Context, values ...*%[3]s) (int64, error) {\n", table.rawName, funcName, m.goTableType(table))
+		fmt.Fprintf(w, "db.mu.Lock()\n")
+		fmt.Fprintf(w, "defer db.mu.Unlock()\n")
+		fmt.Fprintf(w, "t := &db.table%s\n", table.rawName)
+		if softDelete != nil {
+			// the database sets CURRENT_TIMESTAMP, but the fake uses Now so that tests can replace it.
+			m.imports.add("time", "time")
+			fmt.Fprintf(w, "now := Now().Truncate(%s)\n", goFractionalSeconds(softDelete.size))
+		}
+		fmt.Fprintf(w, "var rowsAffected int64\n")
+		fmt.Fprintf(w, "for _, v := range values {\n")
+		fmt.Fprintf(w, "key, ok := newFake%sKey(v)\n", table.rawName)
+		fmt.Fprintf(w, "row := t.rows[key]\n")
+		if softDelete == nil {
+			fmt.Fprintf(w, "if !ok || row == nil {\n continue \n}\n")
+			fmt.Fprintf(w, "delete(t.rows, key)\n")
+		} else {
+			fmt.Fprintf(w, "if !ok || row == nil || %s {\n continue \n}\n", goFakeDeleted(table, "row"))
+			if softDelete.fieldType.Kind() == reflect.Pointer {
+				fmt.Fprintf(w, "deletedAt := now\n")
+				fmt.Fprintf(w, "row.v.%s = &deletedAt\n", softDelete.rawName)
+			} else {
+				_, value, _ := goValue(softDelete, "row.v")
+				fmt.Fprintf(w, "%s = now\n", value)
+				fmt.Fprintf(w, "row.v.%s.Valid = true\n", softDelete.rawName)
+			}
+		}
+		fmt.Fprintf(w, "rowsAffected++\n")
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "return rowsAffected, nil\n")
+		fmt.Fprintf(w, "}\n\n")
+	}
+
+	col := table.softDeleteColumn()
+	generate("Delete", col)
+	if col != nil {
+		generate("HardDelete", nil)
+	}
+}
@@ -0,0 +1,86 @@
+package myddlmaker
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// goImports collects the packages imported by the generated Go code.
+type goImports struct {
+	// pkgPath is the path of the package of the generated Go code.
+	pkgPath string
+
+	// names maps the package paths to the package names.
+	names map[string]string
+}
+
+func newGoImports(pkgPath string) *goImports {
+	return &goImports{
+		pkgPath: pkgPath,
+		names:   map[string]string{},
+	}
+}
+
+// add adds the package to the imports.
+func (imp *goImports) add(pkgPath, name string) {
+	if pkgPath == imp.pkgPath {
+		return
+	}
+	imp.names[pkgPath] = name
+}
+
+// typeName returns the name of typ in the generated Go code,
+// and adds the packages that the name refers to.
+func (imp *goImports) typeName(typ reflect.Type) string {
+	if typ.Name() != "" {
+		switch typ.PkgPath() {
+		case "":
+			// predeclared types
+			return typ.Name()
+		case imp.pkgPath:
+			return typ.Name()
+		}
+
+		// typ.String() is qualified with the package name, e.g. "sql.NullString".
+		s := typ.String()
+		name, _, _ := strings.Cut(s, ".")
+		imp.add(typ.PkgPath(), name)
+		return s
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return "*" + imp.typeName(typ.Elem())
+	case reflect.Slice:
+		return "[]" + imp.typeName(typ.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", typ.Len(), imp.typeName(typ.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", imp.typeName(typ.Key()), imp.typeName(typ.Elem()))
+	}
+	return typ.String()
+}
+
+// specs returns the import specs sorted by the package paths.
+func (imp *goImports) specs() []string {
+	paths := make([]string, 0, len(imp.names))
+	for p := range imp.names {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	specs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		name := imp.names[p]
+		if name == path.Base(p) {
+			specs = append(specs, strconv.Quote(p))
+		} else {
+			specs = append(specs, name+" "+strconv.Quote(p))
+		}
+	}
+	return specs
+}
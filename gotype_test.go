@@ -0,0 +1,59 @@
+package myddlmaker
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGoImports_TypeName(t *testing.T) {
+	imp := newGoImports("github.com/shogo82148/myddlmaker")
+	testcases := []struct {
+		in   reflect.Type
+		want string
+	}{
+		{
+			in:   reflect.TypeOf(int32(0)),
+			want: "int32",
+		},
+		{
+			in:   reflect.TypeOf([]byte{}),
+			want: "[]uint8",
+		},
+		{
+			in:   reflect.TypeOf([4]byte{}),
+			want: "[4]uint8",
+		},
+		{
+			in:   reflect.TypeOf((*int64)(nil)),
+			want: "*int64",
+		},
+		{
+			in:   reflect.TypeOf(time.Time{}),
+			want: "time.Time",
+		},
+		{
+			in:   reflect.TypeOf(map[string]*sql.NullString{}),
+			want: "map[string]*sql.NullString",
+		},
+		{
+			in:   reflect.TypeOf(customType{}),
+			want: "customType",
+		},
+	}
+
+	for _, tc := range testcases {
+		got := imp.typeName(tc.in)
+		if got != tc.want {
+			t.Errorf("%v: want %q, got %q", tc.in, tc.want, got)
+		}
+	}
+
+	want := []string{`"database/sql"`, `"time"`}
+	if diff := cmp.Diff(want, imp.specs()); diff != "" {
+		t.Errorf("unexpected imports (-want/+got):\n%s", diff)
+	}
+}
@@ -35,10 +35,8 @@ type Index struct {
 }
 
 // NewIndex returns a new index.
+// If name is empty, it is generated from the naming convention.
 func NewIndex(name string, col ...string) *Index {
-	if name == "" {
-		panic("name is missing")
-	}
 	if len(col) == 0 {
 		panic("col is missing")
 	}
@@ -107,10 +105,8 @@ type UniqueIndex struct {
 }
 
 // NewUniqueIndex returns a new unique index.
+// If name is empty, it is generated from the naming convention.
 func NewUniqueIndex(name string, col ...string) *UniqueIndex {
-	if name == "" {
-		panic("name is missing")
-	}
 	if len(col) == 0 {
 		panic("col is missing")
 	}
@@ -185,10 +181,8 @@ const (
 )
 
 // NewForeignKey returns a new foreign key constraint.
+// If name is empty, it is generated from the naming convention.
 func NewForeignKey(name string, columns []string, table string, references []string) *ForeignKey {
-	if name == "" {
-		panic("name is missing")
-	}
 	if table == "" {
 		panic("table is missing")
 	}
@@ -248,10 +242,8 @@ type FullTextIndex struct {
 }
 
 // NewFullTextIndex returns a new full text index.
+// If name is empty, it is generated from the naming convention.
 func NewFullTextIndex(name string, columns ...string) *FullTextIndex {
-	if name == "" {
-		panic("name is missing")
-	}
 	if len(columns) == 0 {
 		panic("columns is missing")
 	}
@@ -303,10 +295,8 @@ type SpatialIndex struct {
 }
 
 // NewSpatialIndex returns a new spatial index.
+// If name is empty, it is generated from the naming convention.
 func NewSpatialIndex(name string, column string) *SpatialIndex {
-	if name == "" {
-		panic("name is missing")
-	}
 	if column == "" {
 		panic("column is missing")
 	}
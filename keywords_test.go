@@ -0,0 +1,25 @@
+package myddlmaker
+
+import "testing"
+
+func TestIsReservedWord(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"order", true},
+		{"ORDER", true},
+		{"Group", true},
+		{"key", true},
+		{"qualify", true}, // added in MySQL 8.4
+		{"name", false},
+		{"json", false}, // JSON is a keyword, but not reserved
+		{"order_", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsReservedWord(tt.in); got != tt.want {
+			t.Errorf("IsReservedWord(%q) = %t, want %t", tt.in, got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"go/format"
+	"go/token"
 	"io"
+	"log"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -21,6 +26,10 @@ type Config struct {
 	// If it is empty, "schema_gen.go" is used.
 	OutGoFilePath string
 
+	// OutFakeFilePath is a file path for the in-memory fake generated by GenerateFakeFile.
+	// If it is empty, "schema_fake.go" is used.
+	OutFakeFilePath string
+
 	// PackageName is a package name for Go source code generated by the DDL Maker.
 	// If it is empty, "schema" is used.
 	PackageName string
@@ -29,8 +38,59 @@ type Config struct {
 	// If it is empty, "myddlmaker" is used.
 	Tag string
 
+	// StructPackagePath is the import path of the package that declares the structs.
+	// If it is set, the generated Go code refers to the structs with the package name,
+	// so it can be placed in another package named PackageName.
+	// The structs, their fields and the types of the fields must be exported.
+	// If it is empty, the generated Go code must be in the same package as the structs.
+	StructPackagePath string
+
+	// FakeTag is a build constraint tag for the in-memory fake generated by GenerateFakeFile.
+	// The fake is built only if the tag is set.
+	// If it is empty, "fake" is used.
+	FakeTag string
+
 	// SkipValidationFKIndex disables index validation for foreign key constraints.
 	SkipValidationFKIndex bool
+
+	// AutoCreateFKIndex adds the indexes required by foreign key constraints
+	// if the referencing columns are not indexed.
+	// InnoDB creates them implicitly, so it keeps the generated DDL same as the actual schema.
+	// The indexes are named after the constraints.
+	AutoCreateFKIndex bool
+
+	// LenientTagParsing parses the struct tags in the same way as older versions.
+	// Unknown options are ignored, duplicated options overwrite previous ones,
+	// and the quotes in the values are kept as they are.
+	LenientTagParsing bool
+
+	// Naming is the naming conventions of tables, columns, indexes and constraints.
+	// It is used for generating the omitted names of indexes and constraints,
+	// and for validating names.
+	Naming *NamingConfig
+
+	// UpsertRowAlias makes the generated UpsertX functions use the row alias syntax,
+	// e.g. INSERT INTO ... VALUES (...) AS `new` ON DUPLICATE KEY UPDATE `col` = `new`.`col`.
+	// It requires MySQL 8.0.19 or later.
+	// If it is false, the VALUES() function is used, which is deprecated since MySQL 8.0.20.
+	UpsertRowAlias bool
+
+	// GenerateIterators generates the IterAllX functions that return iter.Seq2.
+	// The generated Go code requires Go 1.23 or later.
+	GenerateIterators bool
+
+	// GenerateQueries generates the Queries type in addition to the functions.
+	// It has the same methods as the generated functions, and caches the prepared statements.
+	GenerateQueries bool
+
+	// GenerateHooks generates the Hooks interface and SetHooks function.
+	// The hooks are called around the queries issued by the generated functions.
+	GenerateHooks bool
+
+	// GenerateSQLCommenter generates the SetCommenter function.
+	// The comments built by the commenter are prefixed to the statements in sqlcommenter format.
+	// https://google.github.io/sqlcommenter/spec/
+	GenerateSQLCommenter bool
 }
 
 type DBConfig struct {
@@ -46,8 +106,16 @@ type DBConfig struct {
 
 type Maker struct {
 	config  *Config
+	naming  *naming
 	structs []any
 	tables  []*table
+
+	// imports is the packages imported by the generated Go code.
+	imports *goImports
+
+	// uncachedFuncs is the generated functions that build the queries at runtime.
+	// The methods of Queries don't cache their statements.
+	uncachedFuncs map[string]bool
 }
 
 func New(config *Config) (*Maker, error) {
@@ -64,13 +132,31 @@ func New(config *Config) (*Maker, error) {
 			Charset: db.Charset,
 			Collate: db.Collate,
 		},
-		OutFilePath:   withDefault(config.OutFilePath, "schema.sql"),
-		OutGoFilePath: withDefault(config.OutGoFilePath, "schema_gen.go"),
-		PackageName:   withDefault(config.PackageName, "schema"),
-		Tag:           withDefault(config.Tag, "myddlmaker"),
+		OutFilePath:       withDefault(config.OutFilePath, "schema.sql"),
+		OutGoFilePath:     withDefault(config.OutGoFilePath, "schema_gen.go"),
+		OutFakeFilePath:   withDefault(config.OutFakeFilePath, "schema_fake.go"),
+		PackageName:       withDefault(config.PackageName, "schema"),
+		Tag:               withDefault(config.Tag, "myddlmaker"),
+		StructPackagePath: config.StructPackagePath,
+		FakeTag:           withDefault(config.FakeTag, "fake"),
+
+		SkipValidationFKIndex: config.SkipValidationFKIndex,
+		AutoCreateFKIndex:     config.AutoCreateFKIndex,
+		LenientTagParsing:     config.LenientTagParsing,
+		Naming:                config.Naming,
+		UpsertRowAlias:        config.UpsertRowAlias,
+		GenerateIterators:     config.GenerateIterators,
+		GenerateQueries:       config.GenerateQueries,
+		GenerateHooks:         config.GenerateHooks,
+		GenerateSQLCommenter:  config.GenerateSQLCommenter,
+	}
+	naming, err := newNaming(c.Naming)
+	if err != nil {
+		return nil, err
 	}
 	return &Maker{
 		config: c,
+		naming: naming,
 	}, nil
 }
 
@@ -123,12 +209,25 @@ func (m *Maker) Generate(w io.Writer) error {
 func (m *Maker) parse() error {
 	m.tables = make([]*table, len(m.structs))
 	for i, s := range m.structs {
-		tbl, err := newTable(s)
+		tbl, err := newTable(s, m.config.LenientTagParsing)
 		if err != nil {
 			return fmt.Errorf("myddlmaker: failed to parse: %w", err)
 		}
 		m.tables[i] = tbl
 	}
+	if err := m.naming.renameReservedWords(m.tables); err != nil {
+		return err
+	}
+	for _, tbl := range m.tables {
+		if err := m.naming.nameTable(tbl); err != nil {
+			return err
+		}
+	}
+	if m.config.AutoCreateFKIndex {
+		for _, tbl := range m.tables {
+			tbl.addFKIndexes()
+		}
+	}
 	if err := m.validate(); err != nil {
 		return err
 	}
@@ -138,6 +237,8 @@ func (m *Maker) parse() error {
 func (m *Maker) validate() error {
 	v := newValidator(m.tables)
 	v.SkipValidationFKIndex = m.config.SkipValidationFKIndex
+	v.Naming = m.config.Naming
+	v.DB = m.config.DB
 	return v.Validate()
 }
 
@@ -400,10 +501,25 @@ func (m *Maker) GenerateGo(w io.Writer) error {
 		return err
 	}
 
-	m.generateGoHeader(&buf)
+	if err := m.initGoImports(); err != nil {
+		return err
+	}
+	m.uncachedFuncs = map[string]bool{}
+
+	// the header is generated after the body,
+	// because the imports are collected while generating the body.
+	var body bytes.Buffer
 	for _, table := range m.tables {
-		m.generateGoTable(&buf, table)
+		m.generateGoTable(&body, table)
 	}
+	m.generateGoLockMode(&body)
+	m.generateGoHooks(&body)
+	m.generateGoCommenter(&body)
+	m.generateGoErrStaleObject(&body)
+	m.generateGoNow(&body)
+	m.generateGoQueries(&body)
+	m.generateGoHeader(&buf)
+	buf.Write(body.Bytes())
 
 	source, err := format.Source(buf.Bytes())
 	if err != nil {
@@ -413,15 +529,79 @@ func (m *Maker) GenerateGo(w io.Writer) error {
 	return err
 }
 
+// initGoImports initializes the imports of the generated Go code.
+func (m *Maker) initGoImports() error {
+	pkgPath := m.config.StructPackagePath
+	if pkgPath == "" {
+		// the generated Go code is in the same package as the structs.
+		if len(m.tables) > 0 {
+			pkgPath = m.tables[0].pkgPath
+		}
+		m.imports = newGoImports(pkgPath)
+		return nil
+	}
+
+	for _, table := range m.tables {
+		if table.pkgPath != pkgPath {
+			return fmt.Errorf("myddlmaker: %s is not declared in the package %q", table.typ, pkgPath)
+		}
+	}
+
+	// the generated Go code in another package can't refer to the unexported names.
+	for _, table := range m.tables {
+		if !token.IsExported(table.typ.Name()) {
+			return fmt.Errorf("myddlmaker: %s is not exported, so it can't be used from another package", table.typ)
+		}
+		for _, c := range table.columns {
+			if !token.IsExported(c.rawName) {
+				return fmt.Errorf("myddlmaker: the field %s of %s is not exported, so it can't be used from another package", c.rawName, table.typ)
+			}
+			if typ := unexportedType(c.fieldType); typ != nil {
+				return fmt.Errorf("myddlmaker: the type %s of the field %s.%s is not exported, so it can't be used from another package", typ, table.typ, c.rawName)
+			}
+		}
+	}
+
+	// the generated Go code is in another package,
+	// so the types in the package of the structs are qualified too.
+	m.imports = newGoImports("")
+	return nil
+}
+
+// unexportedType returns the unexported named type in typ, or nil if there is no such type.
+func unexportedType(typ reflect.Type) reflect.Type {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return unexportedType(typ.Elem())
+	case reflect.Map:
+		if t := unexportedType(typ.Key()); t != nil {
+			return t
+		}
+		return unexportedType(typ.Elem())
+	}
+	if typ.PkgPath() != "" && !token.IsExported(typ.Name()) {
+		return typ
+	}
+	return nil
+}
+
+// goTableType returns the name of the struct type of table in the generated Go code.
+func (m *Maker) goTableType(table *table) string {
+	return m.imports.typeName(table.typ)
+}
+
 func (m *Maker) generateGoHeader(w io.Writer) {
 	io.WriteString(w, "// Code generated by https://github.com/shogo82148/myddlmaker; DO NOT EDIT.\n\n")
 	fmt.Fprintf(w, "//go:build !%s\n\n", m.config.Tag)
 	fmt.Fprintf(w, "package %s\n\n", m.config.PackageName)
-	fmt.Fprintf(w, `import (
-		"context"
-		"database/sql"
-	)
-
+	m.imports.add("context", "context")
+	m.imports.add("database/sql", "sql")
+	fmt.Fprintf(w, "import (\n")
+	for _, spec := range m.imports.specs() {
+		fmt.Fprintf(w, "%s\n", spec)
+	}
+	fmt.Fprintf(w, ")\n\n")
+	fmt.Fprintf(w, `
 	type execer interface {
 		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 		PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
@@ -437,24 +617,222 @@ func (m *Maker) generateGoHeader(w io.Writer) {
 }
 
 func (m *Maker) generateGoTable(w io.Writer, table *table) {
+	m.generateGoTableColumns(w, table)
 	m.generateGoTableInsert(w, table)
-	m.generateGoTableSelect(w, table)
-	m.generateGoTableSelectAll(w, table)
+	reads := []readGenerator{
+		m.generateGoTableSelect,
+		m.generateGoTableSelectByPrimaryKeys,
+		m.generateGoTableSelectByUniqueIndexes,
+		m.generateGoTableSelectAll,
+		m.generateGoTableIterAll,
+		m.generateGoTableListByIndexes,
+	}
+	filters := readFilters(table)
+	for _, read := range reads {
+		for _, filter := range filters {
+			read(w, table, filter)
+		}
+	}
+	m.generateGoTableSelectWithLock(w, table)
+	m.generateGoTableForeignKeys(w, table)
 	m.generateGoTableUpdate(w, table)
+	m.generateGoTableUpdateColumns(w, table)
+	m.generateGoTableDelete(w, table)
+	m.generateGoTableUpsert(w, table)
+}
+
+// readGenerator generates a read function for the filter.
+type readGenerator func(w io.Writer, table *table, filter readFilter)
+
+// readFilter is a variant of the generated read functions.
+type readFilter struct {
+	// suffix is the suffix of the function names.
+	suffix string
+
+	// condition is the SQL condition to filter the rows.
+	// It is empty if the rows are not filtered.
+	condition string
+}
+
+// readFilters returns the variants of the read functions of the table.
+// The first one is the default variant, and its suffix is empty.
+func readFilters(table *table) []readFilter {
+	col := table.softDeleteColumn()
+	if col == nil {
+		return []readFilter{{}}
+	}
+	return []readFilter{
+		{condition: quote(col.name) + " IS NULL"},
+		{suffix: "IncludingDeleted"},
+	}
+}
+
+// conditions returns the conditions of f.
+func (f readFilter) conditions() []string {
+	if f.condition == "" {
+		return nil
+	}
+	return []string{f.condition}
+}
+
+// generateDoc writes the doc comment of the non-default variant of the function name.
+// It reports whether the doc comment is written.
+func (f readFilter) generateDoc(w io.Writer, name string) bool {
+	if f.suffix == "" {
+		return false
+	}
+	fmt.Fprintf(w, "// %[1]s%[2]s is same as %[1]s, but it includes the soft-deleted rows.\n", name, f.suffix)
+	return true
+}
+
+// https://stackoverflow.com/questions/18100782/import-of-50k-records-in-mysql-gives-general-error-1390-prepared-statement-con
+const maxPlaceholderCount = 65535
+
+// maxMaxStructCount is the maximum number of structs in one statement.
+const maxMaxStructCount = 32
+
+// structCountPerStatement returns the number of structs in one statement.
+func structCountPerStatement(fieldCount int) int {
+	return min(maxPlaceholderCount/fieldCount, maxMaxStructCount)
+}
+
+// goColumnName returns the name of the XColumn constant for the column.
+func goColumnName(table *table, c *column) string {
+	return table.rawName + "Column" + c.rawName
+}
+
+func (m *Maker) generateGoTableColumns(w io.Writer, table *table) {
+	fmt.Fprintf(w, "// %[1]sColumn is a column of %[2]s.\n", table.rawName, quote(table.name))
+	fmt.Fprintf(w, "type %sColumn string\n\n", table.rawName)
+	fmt.Fprintf(w, "const (\n")
+	for _, c := range table.columns {
+		fmt.Fprintf(w, "%s %sColumn = %q\n", goColumnName(table, c), table.rawName, c.name)
+	}
+	fmt.Fprintf(w, ")\n\n")
+
+	// the names for hand-written queries.
+	fmt.Fprintf(w, "// %sTable is the name of the table %s.\n", table.rawName, quote(table.name))
+	fmt.Fprintf(w, "const %sTable = %q\n\n", table.rawName, table.name)
+
+	m.imports.add("strings", "strings")
+	fmt.Fprintf(w, "// Quoted returns the name of the column quoted with backquotes.\n")
+	fmt.Fprintf(w, "func (c %sColumn) Quoted() string {\n", table.rawName)
+	fmt.Fprintf(w, "return \"`\" + strings.ReplaceAll(string(c), \"`\", \"``\") + \"`\"\n")
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(w, "// %sAllColumns is all the columns of %s in the order of the table definition.\n", table.rawName, quote(table.name))
+	fmt.Fprintf(w, "var %sAllColumns = []%sColumn{\n", table.rawName, table.rawName)
+	for _, c := range table.columns {
+		fmt.Fprintf(w, "%s,\n", goColumnName(table, c))
+	}
+	fmt.Fprintf(w, "}\n\n")
 }
 
 func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
-	// https://stackoverflow.com/questions/18100782/import-of-50k-records-in-mysql-gives-general-error-1390-prepared-statement-con
-	const maxPlaceholderCount = 65535
-	const maxMaxStructCount = 32
+	// the auto increment column that is assigned after inserting.
+	var auto *column
+	for _, c := range table.columns {
+		if c.autoIncr && isIntegerKind(c.rawType.Kind()) {
+			auto = c
+		}
+	}
+
+	created, updated := table.createdColumn(), table.updatedColumn()
+	var timestamps []string
+	for _, c := range []*column{created, updated} {
+		if c != nil {
+			timestamps = append(timestamps, c.rawName)
+		}
+	}
+
+	if auto != nil {
+		fmt.Fprintf(w, "// Insert%[1]s inserts the values, and assigns the generated IDs to their %[2]s fields.\n", table.rawName, auto.rawName)
+		fmt.Fprintf(w, "// The IDs are computed from LastInsertId and the number of rows in each batch.\n")
+		fmt.Fprintf(w, "// It assumes the IDs generated by one INSERT statement are consecutive,\n")
+		fmt.Fprintf(w, "// which InnoDB guarantees for simple inserts in all innodb_autoinc_lock_mode.\n")
+		fmt.Fprintf(w, "// The assumption doesn't hold if auto_increment_increment is not 1.\n")
+	} else if len(timestamps) > 0 {
+		fmt.Fprintf(w, "// Insert%s inserts the values.\n", table.rawName)
+	}
+	if len(timestamps) > 0 {
+		fmt.Fprintf(w, "// It sets the %s fields to the current time returned by Now.\n", strings.Join(timestamps, " and "))
+	}
+	fn := goFunc{name: "Insert" + table.rawName, conn: "execer", params: []string{"values ...*" + m.goTableType(table)}, results: "error"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "return insert%[1]s(ctx, execer, \"\", %[2]t, values...)\n", table.rawName, auto != nil)
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+
+	m.generateGoTableInsertHelper(w, table, "insert", auto, false)
+}
 
-	fmt.Fprintf(w, "func Insert%[1]s(ctx context.Context, execer execer, values ...*%[1]s) error {", table.rawName)
+// generateGoTableInsertHelper generates the function named name+table.rawName that inserts the values
+// with the suffix, e.g. ON DUPLICATE KEY UPDATE clause.
+// If withAutoIncr is true, the auto increment columns are inserted with the values of the fields,
+// otherwise they are omitted and the generated IDs are assigned to the field of auto.
+func (m *Maker) generateGoTableInsertHelper(w io.Writer, table *table, name string, auto *column, withAutoIncr bool) {
+	created, updated := table.createdColumn(), table.updatedColumn()
+	var timestamps []string
+	for _, c := range []*column{created, updated} {
+		if c != nil {
+			timestamps = append(timestamps, c.rawName)
+		}
+	}
+
+	// exec returns the Go code that executes the statement,
+	// and assigns the generated IDs to vals if assignIDs is true.
+	exec := func(call string) string {
+		if auto == nil {
+			return fmt.Sprintf("if _, err := %s; err != nil {\nreturn err\n}", call)
+		}
+		typ := m.imports.typeName(auto.rawType)
+		var assign string
+		if auto.fieldType.Kind() == reflect.Pointer {
+			assign = fmt.Sprintf("id := %s(id + int64(i))\nv.%s = &id", typ, auto.rawName)
+		} else {
+			assign = fmt.Sprintf("v.%s = %s(id + int64(i))", auto.rawName, typ)
+		}
+		return fmt.Sprintf(`result, err := %s
+		if err != nil {
+			return err
+		}
+		if assignIDs {
+			id, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			for i, v := range vals {
+				%s
+			}
+		}`, call, assign)
+	}
+
+	// insertX inserts the values with the suffix, e.g. ON DUPLICATE KEY UPDATE clause.
+	// If assignIDs is true, it assigns the generated IDs to the values.
+	fmt.Fprintf(w, "func %[1]s%[2]s(ctx context.Context, execer execer, suffix string, assignIDs bool, values ...*%[3]s) error {\n", name, table.rawName, m.goTableType(table))
+	if m.config.GenerateHooks || m.config.GenerateSQLCommenter {
+		fmt.Fprintf(w, "operation := \"insert\"\n")
+		fmt.Fprintf(w, "if suffix != \"\" {\n operation = \"upsert\" \n}\n")
+	}
+	if len(timestamps) > 0 {
+		fmt.Fprintf(w, "now := Now()\n")
+		fmt.Fprintf(w, "for _, v := range values {\n")
+		if created != nil {
+			// the existing rows keep their created column on upserts,
+			// so the created time of the values is kept unless it is zero.
+			fmt.Fprintf(w, "if suffix == \"\" || v.%s.IsZero() {\n", created.rawName)
+			m.generateGoSetTimestamps(w, "v", created)
+			fmt.Fprintf(w, "}\n")
+		}
+		m.generateGoSetTimestamps(w, "v", updated)
+		fmt.Fprintf(w, "}\n")
+	}
 
 	columns := make([]string, 0, len(table.columns))
 	placeholders := make([]string, 0, len(table.columns))
 	values := make([]string, 0, len(table.columns))
 	for _, c := range table.columns {
-		if c.autoIncr {
+		if c.autoIncr && !withAutoIncr {
 			continue
 		}
 		columns = append(columns, quote(c.name))
@@ -469,17 +847,16 @@ func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 		fmt.Fprintf(w, "const maxStructCount = %d\n", maxMaxStructCount)
 		fmt.Fprintf(w, `if len(values) >= maxStructCount {
 			err := func() error {
-				stmt, err := execer.PrepareContext(ctx, q)
+				stmt, err := %[4]s
 				if err != nil {
 					return err
 				}
-				defer stmt.Close()
+				defer %[3]s
 
 				for len(values) >= maxStructCount {
-					values = values[maxStructCount:]
-					if _, err := stmt.ExecContext(ctx); err != nil {
-						return err
-					}
+					vals, rest := values[:maxStructCount], values[maxStructCount:]
+					%[1]s
+					values = rest
 				}
 				return nil
 			}()
@@ -490,21 +867,17 @@ func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 		if len(values) == 0 {
 			return nil
 		}
-		if _, err := execer.ExecContext(ctx, q[:len(values)*%[1]d+%[2]d]); err != nil {
-			return err
-		}
+		vals := values
+		%[2]s
 		return nil
 	}
 
-	`, len(strPlaceholders), len(insert)-len(strPlaceholders))
+	`, exec(m.goExec(table, "operation", "stmt", "q+suffix")), exec(m.goExec(table, "operation", "execer", fmt.Sprintf("q[:len(vals)*%d+%d]+suffix", len(strPlaceholders), len(insert)-len(strPlaceholders)))), m.goCloseStmt("execer"), m.goPrepare(table, "operation", "execer", "q+suffix"))
 		return
 	}
 
 	strPlaceholders := ", (" + strings.Join(placeholders, ", ") + ")"
-	maxStructCount := maxPlaceholderCount / len(placeholders)
-	if maxStructCount > maxMaxStructCount {
-		maxStructCount = maxMaxStructCount
-	}
+	maxStructCount := structCountPerStatement(len(placeholders))
 	insert := "INSERT INTO " + quote(table.name) + " (" + strings.Join(columns, ", ") + ") VALUES" + " (" + strings.Join(placeholders, ", ") + ")"
 	fmt.Fprintf(w, "const q = %q+\n%q\n", insert, strings.Repeat(strPlaceholders, maxStructCount-1))
 	fmt.Fprintf(w, "const fieldCount = %d\n", len(placeholders))
@@ -514,11 +887,11 @@ func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 	if len(values) >= maxStructCount {
 		args = make([]any, 0, maxStructCount*fieldCount)
 		err := func() error {
-			stmt, err := execer.PrepareContext(ctx, q)
+			stmt, err := %[5]s
 			if err != nil {
 				return err
 			}
-			defer stmt.Close()
+			defer %[4]s
 
 			for len(values) >= maxStructCount {
 				vals, rest := values[:maxStructCount], values[maxStructCount:]
@@ -526,9 +899,7 @@ func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 				for _, v := range vals {
 					args = append(args, %[1]s)
 				}
-				if _, err := stmt.ExecContext(ctx, args...); err != nil {
-					return err
-				}
+				%[2]s
 				values = rest
 			}
 			return nil
@@ -544,19 +915,28 @@ func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 		args = make([]any, 0, len(values)*fieldCount)
 	}
 	args = args[:0]
-	for _, v := range values {
+	vals := values
+	for _, v := range vals {
 		args = append(args, %[1]s)
 	}
-	if _, err := execer.ExecContext(ctx, q[:len(values)*%[2]d+%[3]d], args...); err != nil {
-		return err
-	}
+	%[3]s
 	return nil
 }
 
-`, strings.Join(values, ", "), len(strPlaceholders), len(insert)-len(strPlaceholders))
+`, strings.Join(values, ", "), exec(m.goExec(table, "operation", "stmt", "q+suffix", "args...")), exec(m.goExec(table, "operation", "execer", fmt.Sprintf("q[:len(vals)*%d+%d]+suffix", len(strPlaceholders), len(insert)-len(strPlaceholders)), "args...")), m.goCloseStmt("execer"), m.goPrepare(table, "operation", "execer", "q+suffix"))
+}
+
+// isIntegerKind reports whether k is an integer kind.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
 }
 
-func (m *Maker) generateGoTableSelect(w io.Writer, table *table) {
+func (m *Maker) generateGoTableSelect(w io.Writer, table *table, filter readFilter) {
 	fields := make([]string, 0, len(table.columns))
 	goFields := make([]string, 0, len(table.columns))
 	params := make([]string, 0, len(table.primaryKey.columns))
@@ -571,6 +951,7 @@ func (m *Maker) generateGoTableSelect(w io.Writer, table *table) {
 			}
 		}
 	}
+	conditions = append(conditions, filter.conditions()...)
 
 	sqlSelect := fmt.Sprintf(
 		"SELECT %s FROM %s WHERE %s",
@@ -578,15 +959,224 @@ func (m *Maker) generateGoTableSelect(w io.Writer, table *table) {
 		quote(table.name),
 		strings.Join(conditions, " AND "),
 	)
-	fmt.Fprintf(w, "func Select%[1]s(ctx context.Context, queryer queryer, primaryKeys *%[1]s) (*%[1]s, error) {\n", table.rawName)
-	fmt.Fprintf(w, "var v %s\n", table.rawName)
-	fmt.Fprintf(w, "row := queryer.QueryRowContext(ctx, %q, %s)\n", sqlSelect, strings.Join(params, ", "))
-	fmt.Fprintf(w, "if err := row.Scan(%s); err != nil {\n return nil, err \n}\n", strings.Join(goFields, ", "))
+	filter.generateDoc(w, "Select"+table.rawName)
+	fn := goFunc{
+		name:    "Select" + table.rawName + filter.suffix,
+		conn:    "queryer",
+		params:  []string{"primaryKeys *" + m.goTableType(table)},
+		results: "(*" + m.goTableType(table) + ", error)",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "%s\n", m.goQueryRow(table, "queryer", strconv.Quote(sqlSelect), params...))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, err \n}\n", m.goScanRow(goFields))
 	fmt.Fprintf(w, "return &v, nil\n")
 	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+func (m *Maker) generateGoTableSelectByPrimaryKeys(w io.Writer, table *table, filter readFilter) {
+	m.imports.add("strings", "strings")
+
+	fields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		fields = append(fields, quote(c.name))
+		goFields = append(goFields, "&v."+c.rawName)
+		columns[c.name] = c
+	}
+	keys := make([]*column, 0, len(table.primaryKey.columns))
+	quotedKeys := make([]string, 0, len(table.primaryKey.columns))
+	placeholders := make([]string, 0, len(table.primaryKey.columns))
+	params := make([]string, 0, len(table.primaryKey.columns))
+	for _, key := range table.primaryKey.columns {
+		c := columns[key]
+		keys = append(keys, c)
+		quotedKeys = append(quotedKeys, quote(c.name))
+		placeholders = append(placeholders, "?")
+		params = append(params, "key."+c.rawName)
+	}
+
+	// SELECT ... WHERE `id` IN (?, ?, ...)
+	// SELECT ... WHERE (`id1`, `id2`) IN ((?, ?), (?, ?), ...)
+	strKeys := quotedKeys[0]
+	strPlaceholders := "?"
+	if len(keys) > 1 {
+		strKeys = "(" + strings.Join(quotedKeys, ", ") + ")"
+		strPlaceholders = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	sqlSelect := fmt.Sprintf(
+		"SELECT %s FROM %s%s IN (",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		whereClause(append(filter.conditions(), strKeys)),
+	)
+
+	if !filter.generateDoc(w, "Select"+table.rawName+"ByPrimaryKeys") {
+		fmt.Fprintf(w, "// Select%[1]sByPrimaryKeys returns the rows that match the primary keys of keys.\n", table.rawName)
+		fmt.Fprintf(w, "// The keys are split into chunks to respect the limit of the placeholders.\n")
+		fmt.Fprintf(w, "// The rows are not in the order of keys, and the keys that don't match any row are ignored.\n")
+	}
+	m.noStmtCache("Select" + table.rawName + "ByPrimaryKeys" + filter.suffix)
+	fn := goFunc{
+		name:    "Select" + table.rawName + "ByPrimaryKeys" + filter.suffix,
+		conn:    "queryer",
+		params:  []string{"keys ...*" + m.goTableType(table)},
+		results: "([]*" + m.goTableType(table) + ", error)",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "const chunkSize = %d\n", maxPlaceholderCount/len(keys))
+	fmt.Fprintf(w, "var ret []*%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "args := make([]any, 0, min(len(keys), chunkSize)*%d)\n", len(keys))
+	fmt.Fprintf(w, "for len(keys) > 0 {\n")
+	fmt.Fprintf(w, "chunk := keys[:min(len(keys), chunkSize)]\n")
+	fmt.Fprintf(w, "keys = keys[len(chunk):]\n")
+	fmt.Fprintf(w, "args = args[:0]\n")
+	fmt.Fprintf(w, "for _, key := range chunk {\n")
+	fmt.Fprintf(w, "args = append(args, %s)\n", strings.Join(params, ", "))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "q := %q + strings.Repeat(%q, len(chunk)-1) + \")\"\n", sqlSelect+strPlaceholders, ", "+strPlaceholders)
+	fmt.Fprintf(w, "err := func() error {\n")
+	fmt.Fprintf(w, "%s\n", m.goQuery(":=", table, "queryer", "q", "args..."))
+	fmt.Fprintf(w, "if err != nil {\n return err \n}\n")
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return err \n}\n", m.goScanRows(goFields))
+	fmt.Fprintf(w, "ret = append(ret, &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return rows.Err()\n")
+	fmt.Fprintf(w, "}()\n")
+	fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return ret, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+
+	// the map variant.
+	// the values of the pointer fields are used as the map keys.
+	keyExpr := func(c *column) string {
+		if c.fieldType.Kind() == reflect.Pointer {
+			return "*v." + c.rawName
+		}
+		return "v." + c.rawName
+	}
+	for _, c := range keys {
+		if !c.rawType.Comparable() {
+			// e.g. []byte
+			return
+		}
+	}
+	var keyType, key string
+	if len(keys) == 1 {
+		keyType = m.imports.typeName(keys[0].rawType)
+		key = keyExpr(keys[0])
+	} else {
+		keyType = table.rawName + "PrimaryKey"
+		elements := make([]string, 0, len(keys))
+		for _, c := range keys {
+			elements = append(elements, fmt.Sprintf("%s: %s", c.rawName, keyExpr(c)))
+		}
+		key = keyType + "{" + strings.Join(elements, ", ") + "}"
+
+		// the key type is shared by all variants.
+		if filter.suffix == "" {
+			fmt.Fprintf(w, "// %s is the primary key of %s.\n", keyType, quote(table.name))
+			fmt.Fprintf(w, "type %s struct {\n", keyType)
+			for _, c := range keys {
+				fmt.Fprintf(w, "%s %s\n", c.rawName, m.imports.typeName(c.rawType))
+			}
+			fmt.Fprintf(w, "}\n\n")
+		}
+	}
+	if !filter.generateDoc(w, "Select"+table.rawName+"MapByPrimaryKeys") {
+		fmt.Fprintf(w, "// Select%[1]sMapByPrimaryKeys is same as Select%[1]sByPrimaryKeys, but it returns the rows as a map keyed by the primary key.\n", table.rawName)
+	}
+	m.noStmtCache("Select" + table.rawName + "MapByPrimaryKeys" + filter.suffix)
+	fn = goFunc{
+		name:    "Select" + table.rawName + "MapByPrimaryKeys" + filter.suffix,
+		conn:    "queryer",
+		params:  []string{"keys ...*" + m.goTableType(table)},
+		results: "(map[" + keyType + "]*" + m.goTableType(table) + ", error)",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "rows, err := Select%sByPrimaryKeys%s(ctx, queryer, keys...)\n", table.rawName, filter.suffix)
+	fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
+	fmt.Fprintf(w, "ret := make(map[%s]*%s, len(rows))\n", keyType, m.goTableType(table))
+	fmt.Fprintf(w, "for _, v := range rows {\n")
+	fmt.Fprintf(w, "ret[%s] = v\n", key)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return ret, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+func (m *Maker) generateGoTableSelectByUniqueIndexes(w io.Writer, table *table, filter readFilter) {
+	fields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		fields = append(fields, quote(c.name))
+		goFields = append(goFields, "&v."+c.rawName)
+		columns[c.name] = c
+	}
+
+	for _, idx := range table.uniqueIndexes {
+		params := make([]string, 0, len(idx.columns))
+		args := make([]string, 0, len(idx.columns))
+		conditions := make([]string, 0, len(idx.columns))
+		nullable := false
+		for _, name := range idx.columns {
+			c := columns[name]
+			nullable = nullable || c.null
+			arg := goParamName(c.rawName)
+			params = append(params, arg+" "+m.imports.typeName(c.fieldType))
+			args = append(args, arg)
+			conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
+		}
+		conditions = append(conditions, filter.conditions()...)
+
+		sqlSelect := fmt.Sprintf(
+			"SELECT %s FROM %s WHERE %s",
+			strings.Join(fields, ", "),
+			quote(table.name),
+			strings.Join(conditions, " AND "),
+		)
+		funcName := "Select" + table.rawName + "By" + snakeToCamel(idx.name)
+		if !filter.generateDoc(w, funcName) {
+			fmt.Fprintf(w, "// %s returns the row that matches the unique index %s.\n", funcName, quote(idx.name))
+			fmt.Fprintf(w, "// It returns sql.ErrNoRows if no row matches.\n")
+			if nullable {
+				fmt.Fprintf(w, "// A NULL argument never matches any row, because NULL is not equal to NULL in SQL.\n")
+			}
+		}
+		fn := goFunc{name: funcName + filter.suffix, conn: "queryer", params: params, results: "(*" + m.goTableType(table) + ", error)"}
+		fmt.Fprintf(w, "func %s {\n", fn)
+		fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+		fmt.Fprintf(w, "%s\n", m.goQueryRow(table, "queryer", strconv.Quote(sqlSelect), args...))
+		fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, err \n}\n", m.goScanRow(goFields))
+		fmt.Fprintf(w, "return &v, nil\n")
+		fmt.Fprintf(w, "}\n\n")
+		m.generateGoQueriesMethod(w, fn)
+	}
 }
 
-func (m *Maker) generateGoTableSelectAll(w io.Writer, table *table) {
+// goParamName returns the name of the parameter for the field in the generated Go code.
+// It avoids the keywords and the names used in the generated functions.
+func goParamName(rawName string) string {
+	name := lowerCamel(rawName)
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	switch name {
+	case "ctx", "execer", "queryer", "v", "row", "rows", "err", "args", "ret", "cursor", "limit", "next", "q", "hook":
+		return name + "_"
+	}
+	return name
+}
+
+func (m *Maker) generateGoTableSelectAll(w io.Writer, table *table, filter readFilter) {
 	fields := make([]string, 0, len(table.columns))
 	goFields := make([]string, 0, len(table.columns))
 	for _, c := range table.columns {
@@ -599,77 +1189,1906 @@ func (m *Maker) generateGoTableSelectAll(w io.Writer, table *table) {
 	}
 
 	sqlSelect := fmt.Sprintf(
-		"SELECT %s FROM %s ORDER BY %s",
+		"SELECT %s FROM %s%s ORDER BY %s",
 		strings.Join(fields, ", "),
 		quote(table.name),
+		whereClause(filter.conditions()),
 		strings.Join(keys, ", "),
 	)
-	fmt.Fprintf(w, "func SelectAll%[1]s(ctx context.Context, queryer queryer) ([]*%[1]s, error) {\n", table.rawName)
-	fmt.Fprintf(w, "var ret []*%[1]s\n", table.rawName)
-	fmt.Fprintf(w, "rows, err := queryer.QueryContext(ctx, %q)\n", sqlSelect)
+	filter.generateDoc(w, "SelectAll"+table.rawName)
+	fn := goFunc{name: "SelectAll" + table.rawName + filter.suffix, conn: "queryer", results: "([]*" + m.goTableType(table) + ", error)"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "var ret []*%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "%s\n", m.goQuery(":=", table, "queryer", strconv.Quote(sqlSelect)))
 	fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
-	fmt.Fprintf(w, "defer rows.Close()\n")
-	fmt.Fprintf(w, "for rows.Next() {\n")
-	fmt.Fprintf(w, "var v %s\n", table.rawName)
-	fmt.Fprintf(w, "if err := rows.Scan(%s); err != nil {\n return nil, err \n}\n", strings.Join(goFields, ", "))
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, err \n}\n", m.goScanRows(goFields))
 	fmt.Fprintf(w, "ret = append(ret, &v)")
 	fmt.Fprintf(w, "}\n")
 	fmt.Fprintf(w, "if err := rows.Err(); err != nil {\n return nil, err \n}\n")
 	fmt.Fprintf(w, "return ret, nil\n")
 	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
 }
 
-func (m *Maker) generateGoTableUpdate(w io.Writer, table *table) {
-	setFields := make([]string, 0, len(table.columns))
-	goFields := make([]string, 0, len(table.columns))
-	params := make([]string, 0, len(table.primaryKey.columns))
-	conditions := make([]string, 0, len(table.primaryKey.columns))
+func (m *Maker) generateGoTableIterAll(w io.Writer, table *table, filter readFilter) {
+	if !m.config.GenerateIterators {
+		return
+	}
+	m.imports.add("iter", "iter")
 
-LOOP:
+	fields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	columns := make(map[string]*column, len(table.columns))
 	for _, c := range table.columns {
-		for _, key := range table.primaryKey.columns {
-			if key == c.name {
-				params = append(params, fmt.Sprintf("value.%s", c.rawName))
-				conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
-				continue LOOP
-			}
-		}
-		setFields = append(setFields, fmt.Sprintf("%s = ?", quote(c.name)))
-		goFields = append(goFields, "value."+c.rawName)
+		fields = append(fields, quote(c.name))
+		goFields = append(goFields, "&v."+c.rawName)
+		columns[c.name] = c
+	}
+	cursor := make([]cursorColumn, 0, len(table.primaryKey.columns))
+	for _, key := range table.primaryKey.columns {
+		cursor = append(cursor, cursorColumn{column: columns[key]})
 	}
 
-	update := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE %s",
+	sqlSelect := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s",
+		strings.Join(fields, ", "),
 		quote(table.name),
-		strings.Join(setFields, ", "),
-		strings.Join(conditions, " AND "),
+		whereClause(filter.conditions()),
+		keysetOrder(cursor),
 	)
-	fmt.Fprintf(w, "func Update%[1]s(ctx context.Context, execer execer, values ...*%[1]s) error {\n", table.rawName)
-	if len(setFields) != 0 {
-		fmt.Fprintf(w, "stmt, err := execer.PrepareContext(ctx, %q)\n", update)
-		fmt.Fprintf(w, "if err != nil {\n")
-		fmt.Fprintf(w, "return err\n")
-		fmt.Fprintf(w, "}\n")
-		fmt.Fprintf(w, "defer stmt.Close()\n")
-		fmt.Fprintf(w, "for _, value := range values {\n")
-		fmt.Fprintf(w, "if _, err := stmt.ExecContext(ctx, %s, %s); err != nil {\n", strings.Join(goFields, ", "), strings.Join(params, ", "))
-		fmt.Fprintf(w, "return err\n")
-		fmt.Fprintf(w, "}\n")
-		fmt.Fprintf(w, "}\n")
+	if !filter.generateDoc(w, "IterAll"+table.rawName) {
+		fmt.Fprintf(w, "// IterAll%[1]s returns an iterator over all rows of %[2]s in the order of the primary key.\n", table.rawName, quote(table.name))
+		fmt.Fprintf(w, "// It streams the rows with one query, and stops the query if the consumer stops early.\n")
 	}
-	fmt.Fprintf(w, "return nil\n")
+	fn := goFunc{name: "IterAll" + table.rawName + filter.suffix, conn: "queryer", results: "iter.Seq2[*" + m.goTableType(table) + ", error]"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "return func(yield func(*%s, error) bool) {\n", m.goTableType(table))
+	fmt.Fprintf(w, "%s\n", m.goQuery(":=", table, "queryer", strconv.Quote(sqlSelect)))
+	fmt.Fprintf(w, "if err != nil {\n yield(nil, err)\n return \n}\n")
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n yield(nil, err)\n return \n}\n", m.goScanRows(goFields))
+	fmt.Fprintf(w, "if !yield(&v, nil) {\n return \n}\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if err := rows.Err(); err != nil {\n yield(nil, err)\n}\n")
+	fmt.Fprintf(w, "}\n")
 	fmt.Fprintf(w, "}\n\n")
-}
+	m.generateGoQueriesMethod(w, fn)
 
-// ptrInt returns a pointer to int value.
-func ptrInt(v int) *int {
-	return &v
+	// the batched variant
+	m.imports.add("fmt", "fmt")
+	cursorCondition, cursorArgs := keysetCondition(cursor, "last")
+	sqlFirst := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s LIMIT ?",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		whereClause(filter.conditions()),
+		keysetOrder(cursor),
+	)
+	sqlNext := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s LIMIT ?",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		whereClause(append(filter.conditions(), cursorCondition)),
+		keysetOrder(cursor),
+	)
+	if !filter.generateDoc(w, "IterAll"+table.rawName+"Batched") {
+		fmt.Fprintf(w, "// IterAll%[1]sBatched returns an iterator over all rows of %[2]s in the order of the primary key.\n", table.rawName, quote(table.name))
+		fmt.Fprintf(w, "// It reads batchSize rows per query, and doesn't hold the query open while the consumer processes the rows.\n")
+	}
+	fn = goFunc{
+		name:    "IterAll" + table.rawName + "Batched" + filter.suffix,
+		conn:    "queryer",
+		params:  []string{"batchSize int"},
+		results: "iter.Seq2[*" + m.goTableType(table) + ", error]",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "return func(yield func(*%s, error) bool) {\n", m.goTableType(table))
+	fmt.Fprintf(w, "if batchSize <= 0 {\n")
+	fmt.Fprintf(w, "yield(nil, fmt.Errorf(\"%s: invalid batch size: %%d\", batchSize))\n", m.config.PackageName)
+	fmt.Fprintf(w, "return\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "var last *%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "batch := make([]*%s, 0, batchSize)\n", m.goTableType(table))
+	fmt.Fprintf(w, "for {\n")
+	fmt.Fprintf(w, "batch = batch[:0]\n")
+	fmt.Fprintf(w, "err := func() error {\n")
+	fmt.Fprintf(w, "var rows *sql.Rows\n")
+	fmt.Fprintf(w, "var err error\n")
+	m.generateGoDeclareHook(w)
+	fmt.Fprintf(w, "if last == nil {\n")
+	fmt.Fprintf(w, "%s\n", m.goQuery("=", table, "queryer", strconv.Quote(sqlFirst), "batchSize"))
+	fmt.Fprintf(w, "} else {\n")
+	fmt.Fprintf(w, "%s\n", m.goQuery("=", table, "queryer", strconv.Quote(sqlNext), append(slices.Clip(cursorArgs), "batchSize")...))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if err != nil {\n return err \n}\n")
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return err \n}\n", m.goScanRows(goFields))
+	fmt.Fprintf(w, "batch = append(batch, &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return rows.Err()\n")
+	fmt.Fprintf(w, "}()\n")
+	fmt.Fprintf(w, "if err != nil {\n yield(nil, err)\n return \n}\n")
+	fmt.Fprintf(w, "for _, v := range batch {\n")
+	fmt.Fprintf(w, "if !yield(v, nil) {\n return \n}\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if len(batch) < batchSize {\n return \n}\n")
+	fmt.Fprintf(w, "last = batch[len(batch)-1]\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
 }
 
-// valInt returns a value of int pointer.
-func valInt(v *int) int {
-	if v == nil {
-		return 0
+// generateGoTableListByIndexes generates keyset-paginated list functions for each index.
+// The leading columns of the index are the equality conditions,
+// and the rest of the columns and the primary key are the cursor.
+// ListXBy<Index> uses all the columns except the last one as the equality conditions,
+// and ListXBy<Index>Prefix<N> uses the first N columns for the shorter prefixes.
+// InnoDB secondary indexes contain the primary key columns in ascending order,
+// so the queries can use the index without filesort.
+func (m *Maker) generateGoTableListByIndexes(w io.Writer, table *table, filter readFilter) {
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		columns[c.name] = c
+	}
+
+LOOP:
+	for _, idx := range table.indexes {
+		if idx.invisible {
+			// the optimizer doesn't use invisible indexes.
+			continue
+		}
+		for _, name := range idx.columns {
+			if columns[name].null {
+				// NULL values can't be compared in the cursor.
+				if filter.suffix == "" {
+					log.Printf("warning: the list functions of %s.%s are not generated, because %s is nullable", quote(table.name), quote(idx.name), quote(name))
+				}
+				continue LOOP
+			}
+		}
+
+		funcName := "List" + table.rawName + "By" + snakeToCamel(idx.name)
+		m.generateGoTableListByIndex(w, table, filter, idx, len(idx.columns)-1, funcName)
+		for n := len(idx.columns) - 2; n >= 0; n-- {
+			m.generateGoTableListByIndex(w, table, filter, idx, n, funcName+"Prefix"+strconv.Itoa(n))
+		}
+	}
+}
+
+// generateGoTableListByIndex generates the list function funcName
+// that uses the first n columns of the index idx as the equality conditions.
+func (m *Maker) generateGoTableListByIndex(w io.Writer, table *table, filter readFilter, idx *Index, n int, funcName string) {
+	fields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		fields = append(fields, quote(c.name))
+		goFields = append(goFields, "&v."+c.rawName)
+		columns[c.name] = c
+	}
+
+	prefix := idx.columns[:n]
+	cursor := make([]cursorColumn, 0, len(idx.columns)-n+len(table.primaryKey.columns))
+	for _, name := range idx.columns[n:] {
+		cursor = append(cursor, cursorColumn{column: columns[name], desc: idx.order[name] == "DESC"})
+	}
+	for _, key := range table.primaryKey.columns {
+		if !slices.Contains(idx.columns, key) {
+			cursor = append(cursor, cursorColumn{column: columns[key]})
+		}
+	}
+	cursorName := funcName + "Cursor"
+
+	// the cursor type is shared by all variants.
+	if filter.suffix == "" {
+		fmt.Fprintf(w, "// %s is a cursor for %s.\n", cursorName, funcName)
+		fmt.Fprintf(w, "type %s struct {\n", cursorName)
+		for _, c := range cursor {
+			fmt.Fprintf(w, "%s %s\n", c.rawName, m.imports.typeName(c.fieldType))
+		}
+		fmt.Fprintf(w, "}\n\n")
+	}
+
+	// the equality conditions
+	params := make([]string, 0, len(prefix))
+	args := make([]string, 0, len(prefix))
+	conditions := make([]string, 0, len(prefix))
+	for _, name := range prefix {
+		c := columns[name]
+		arg := goParamName(c.rawName)
+		params = append(params, arg+" "+m.imports.typeName(c.fieldType))
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
+	}
+	conditions = append(conditions, filter.conditions()...)
+
+	cursorCondition, cursorArgs := keysetCondition(cursor, "cursor")
+	sqlSelect := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s LIMIT ?",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		whereClause(conditions),
+		keysetOrder(cursor),
+	)
+	sqlSelectWithCursor := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s LIMIT ?",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		whereClause(append(slices.Clip(conditions), cursorCondition)),
+		keysetOrder(cursor),
+	)
+
+	if !filter.generateDoc(w, funcName) {
+		fmt.Fprintf(w, "// %s returns at most limit rows in the order of the index %s.\n", funcName, quote(idx.name))
+		if len(prefix) > 0 {
+			fmt.Fprintf(w, "// The rows are filtered by the equality conditions on %s.\n", strings.Join(quoteAll(prefix), ", "))
+		}
+		fmt.Fprintf(w, "// If cursor is not nil, it returns the rows after the cursor.\n")
+		fmt.Fprintf(w, "// The returned cursor is nil if there are no more rows.\n")
+	}
+	fn := goFunc{
+		name:    funcName + filter.suffix,
+		conn:    "queryer",
+		params:  append(slices.Clip(params), "cursor *"+cursorName, "limit int"),
+		results: "([]*" + m.goTableType(table) + ", *" + cursorName + ", error)",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "if limit <= 0 {\n return nil, nil, nil \n}\n")
+	fmt.Fprintf(w, "var rows *sql.Rows\n")
+	fmt.Fprintf(w, "var err error\n")
+	m.generateGoDeclareHook(w)
+	fmt.Fprintf(w, "// fetch one more row to know whether there are more rows.\n")
+	fmt.Fprintf(w, "if cursor == nil {\n")
+	fmt.Fprintf(w, "%s\n", m.goQuery("=", table, "queryer", strconv.Quote(sqlSelect), append(slices.Clip(args), "limit+1")...))
+	fmt.Fprintf(w, "} else {\n")
+	fmt.Fprintf(w, "%s\n", m.goQuery("=", table, "queryer", strconv.Quote(sqlSelectWithCursor), append(append(slices.Clip(args), cursorArgs...), "limit+1")...))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if err != nil {\n return nil, nil, err \n}\n")
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "var ret []*%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, nil, err \n}\n", m.goScanRows(goFields))
+	fmt.Fprintf(w, "ret = append(ret, &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if err := rows.Err(); err != nil {\n return nil, nil, err \n}\n")
+	fmt.Fprintf(w, "if len(ret) <= limit {\n return ret, nil, nil \n}\n")
+	fmt.Fprintf(w, "ret = ret[:limit]\n")
+	fmt.Fprintf(w, "v := ret[len(ret)-1]\n")
+	fmt.Fprintf(w, "next := &%s{\n", cursorName)
+	for _, c := range cursor {
+		fmt.Fprintf(w, "%[1]s: v.%[1]s,\n", c.rawName)
+	}
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return ret, next, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+// cursorColumn is a column of the cursor for keyset pagination.
+type cursorColumn struct {
+	*column
+	desc bool
+}
+
+// keysetCondition returns the condition to get the rows after the cursor,
+// e.g. (`a` > ? OR (`a` = ? AND `b` > ?)), and the Go expressions of its arguments.
+// The arguments are the fields of the Go variable v.
+func keysetCondition(cursor []cursorColumn, v string) (string, []string) {
+	conditions := make([]string, 0, len(cursor))
+	var args []string
+	for i, c := range cursor {
+		cond := make([]string, 0, i+1)
+		for _, prev := range cursor[:i] {
+			cond = append(cond, fmt.Sprintf("%s = ?", quote(prev.name)))
+			args = append(args, v+"."+prev.rawName)
+		}
+		op := ">"
+		if c.desc {
+			op = "<"
+		}
+		cond = append(cond, fmt.Sprintf("%s %s ?", quote(c.name), op))
+		args = append(args, v+"."+c.rawName)
+		if len(cond) == 1 {
+			conditions = append(conditions, cond[0])
+		} else {
+			conditions = append(conditions, "("+strings.Join(cond, " AND ")+")")
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// keysetOrder returns the ORDER BY clause for keyset pagination.
+func keysetOrder(cursor []cursorColumn) string {
+	orders := make([]string, 0, len(cursor))
+	for _, c := range cursor {
+		if c.desc {
+			orders = append(orders, quote(c.name)+" DESC")
+		} else {
+			orders = append(orders, quote(c.name)+" ASC")
+		}
+	}
+	return strings.Join(orders, ", ")
+}
+
+// whereClause returns the WHERE clause that joins the conditions with AND.
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// generateGoTableSelectWithLock generates the locking read variants of
+// the lookups by the primary key and the unique indexes.
+// They take *sql.Tx instead of queryer, because the locks are released
+// at the end of the statement in autocommit mode.
+func (m *Maker) generateGoTableSelectWithLock(w io.Writer, table *table) {
+	fields, goFields := goFields(table, "v")
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		columns[c.name] = c
+	}
+	filter := readFilters(table)[0]
+
+	generate := func(funcName string, params, args, conditions []string, by string) {
+		conditions = append(conditions, filter.conditions()...)
+		sqlSelect := fmt.Sprintf(
+			"SELECT %s FROM %s WHERE %s",
+			strings.Join(fields, ", "),
+			quote(table.name),
+			strings.Join(conditions, " AND "),
+		)
+		fmt.Fprintf(w, "// %s returns the row of %s by %s with the locking read of mode.\n", funcName, quote(table.name), by)
+		fmt.Fprintf(w, "func %s(ctx context.Context, tx *sql.Tx, %s, mode LockMode) (*%s, error) {\n", funcName, strings.Join(params, ", "), m.goTableType(table))
+		fmt.Fprintf(w, "clause, err := lockClause(mode)\n")
+		fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
+		fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+		fmt.Fprintf(w, "%s\n", m.goQueryRow(table, "tx", strconv.Quote(sqlSelect)+"+clause", args...))
+		fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, err \n}\n", m.goScanRow(goFields))
+		fmt.Fprintf(w, "return &v, nil\n")
+		fmt.Fprintf(w, "}\n\n")
+	}
+
+	// the primary key
+	args := make([]string, 0, len(table.primaryKey.columns))
+	conditions := make([]string, 0, len(table.primaryKey.columns))
+	for _, key := range table.primaryKey.columns {
+		c := columns[key]
+		args = append(args, "primaryKeys."+c.rawName)
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
+	}
+	generate(
+		"Select"+table.rawName+"WithLock",
+		[]string{"primaryKeys *" + m.goTableType(table)},
+		args, conditions, "the primary key",
+	)
+
+	// the unique indexes
+	for _, idx := range table.uniqueIndexes {
+		params := make([]string, 0, len(idx.columns))
+		args := make([]string, 0, len(idx.columns))
+		conditions := make([]string, 0, len(idx.columns))
+		nullable := false
+		for _, name := range idx.columns {
+			c := columns[name]
+			nullable = nullable || c.null
+			arg := goParamName(c.rawName)
+			params = append(params, arg+" "+m.imports.typeName(c.fieldType))
+			args = append(args, arg)
+			conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
+		}
+		generate(
+			"Select"+table.rawName+"By"+snakeToCamel(idx.name)+"WithLock",
+			params, args, conditions, "the unique index "+quote(idx.name),
+		)
+	}
+}
+
+func (m *Maker) generateGoLockMode(w io.Writer) {
+	if len(m.tables) == 0 {
+		return
+	}
+	m.imports.add("fmt", "fmt")
+	fmt.Fprintf(w, `// LockMode is the mode of the locking reads.
+// https://dev.mysql.com/doc/refman/8.0/en/innodb-locking-reads.html
+type LockMode string
+
+const (
+	// LockForUpdate locks the rows as if they are updated.
+	LockForUpdate LockMode = "FOR UPDATE"
+
+	// LockForUpdateNowait is same as LockForUpdate, but fails immediately if the rows are locked by others.
+	LockForUpdateNowait LockMode = "FOR UPDATE NOWAIT"
+
+	// LockForUpdateSkipLocked is same as LockForUpdate, but skips the rows locked by others.
+	LockForUpdateSkipLocked LockMode = "FOR UPDATE SKIP LOCKED"
+
+	// LockForShare locks the rows with shared locks.
+	LockForShare LockMode = "FOR SHARE"
+
+	// LockForShareNowait is same as LockForShare, but fails immediately if the rows are locked by others.
+	LockForShareNowait LockMode = "FOR SHARE NOWAIT"
+
+	// LockForShareSkipLocked is same as LockForShare, but skips the rows locked by others.
+	LockForShareSkipLocked LockMode = "FOR SHARE SKIP LOCKED"
+)
+
+// lockClause returns the locking read clause of mode.
+func lockClause(mode LockMode) (string, error) {
+	switch mode {
+	case LockForUpdate, LockForUpdateNowait, LockForUpdateSkipLocked,
+		LockForShare, LockForShareNowait, LockForShareSkipLocked:
+		return " " + string(mode), nil
+	}
+	return "", fmt.Errorf("%s: invalid lock mode: %%q", mode)
+}
+
+`, m.config.PackageName)
+}
+
+// generateGoTableForeignKeys generates the loaders of the rows related by the foreign keys of table.
+// The forward loader selects the parent row referenced by a child row,
+// the reverse loader selects the child rows referencing a parent row,
+// and the batch loader selects the child rows of many parents at once.
+func (m *Maker) generateGoTableForeignKeys(w io.Writer, table *table) {
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		columns[c.name] = c
+	}
+
+	for _, fk := range table.foreignKeys {
+		parent := m.findTable(fk.table)
+		if parent == nil {
+			continue
+		}
+		parentColumns := make(map[string]*column, len(parent.columns))
+		for _, c := range parent.columns {
+			parentColumns[c.name] = c
+		}
+		cols := make([]*column, 0, len(fk.columns))
+		refs := make([]*column, 0, len(fk.references))
+		for i := range fk.columns {
+			cols = append(cols, columns[fk.columns[i]])
+			refs = append(refs, parentColumns[fk.references[i]])
+		}
+
+		// the names of the loaders.
+		// if the parent is ambiguous, the names of the columns are used instead of the parent.
+		forward := "Select" + parent.rawName + "For" + table.rawName
+		reverse := "Select" + pluralize(table.rawName) + "By" + parent.rawName
+		batch := "Select" + pluralize(table.rawName) + "By" + pluralize(parent.rawName)
+		ambiguous := fk.table == table.name
+		for _, other := range table.foreignKeys {
+			if other != fk && other.table == fk.table {
+				ambiguous = true
+			}
+		}
+		if ambiguous {
+			var names string
+			for _, c := range cols {
+				names += c.rawName
+			}
+			forward += "By" + names
+			reverse = "Select" + pluralize(table.rawName) + "By" + names
+			batch = "Select" + pluralize(table.rawName) + "By" + pluralize(names)
+		}
+
+		m.generateGoTableForeignKeyForward(w, table, parent, fk, forward, cols, refs)
+		m.generateGoTableForeignKeyReverse(w, table, parent, fk, reverse, cols, refs)
+		m.generateGoTableForeignKeyBatch(w, table, parent, fk, batch, cols, refs)
+	}
+}
+
+// findTable returns the table named name.
+// It returns nil if it is not found.
+func (m *Maker) findTable(name string) *table {
+	for _, t := range m.tables {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// goFields returns the quoted names of the columns of table, and the Go expressions to scan them into v.
+func goFields(table *table, v string) ([]string, []string) {
+	fields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	for _, c := range table.columns {
+		fields = append(fields, quote(c.name))
+		goFields = append(goFields, "&"+v+"."+c.rawName)
+	}
+	return fields, goFields
+}
+
+func (m *Maker) generateGoTableForeignKeyForward(w io.Writer, table, parent *table, fk *ForeignKey, funcName string, cols, refs []*column) {
+	fields, goFields := goFields(parent, "v")
+	conditions := make([]string, 0, len(refs)+1)
+	args := make([]string, 0, len(cols))
+	for i := range refs {
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quote(refs[i].name)))
+		args = append(args, "child."+cols[i].rawName)
+	}
+	conditions = append(conditions, readFilters(parent)[0].conditions()...)
+
+	sqlSelect := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s",
+		strings.Join(fields, ", "),
+		quote(parent.name),
+		strings.Join(conditions, " AND "),
+	)
+	fmt.Fprintf(w, "// %s returns the row of %s referenced by child with the foreign key %s.\n", funcName, quote(parent.name), quote(fk.name))
+	if slices.ContainsFunc(cols, func(c *column) bool { return c.null }) {
+		fmt.Fprintf(w, "// It returns sql.ErrNoRows if the foreign key of child is NULL.\n")
+	}
+	fn := goFunc{name: funcName, conn: "queryer", params: []string{"child *" + m.goTableType(table)}, results: "(*" + m.goTableType(parent) + ", error)"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(parent))
+	fmt.Fprintf(w, "%s\n", m.goQueryRow(parent, "queryer", strconv.Quote(sqlSelect), args...))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, err \n}\n", m.goScanRow(goFields))
+	fmt.Fprintf(w, "return &v, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+func (m *Maker) generateGoTableForeignKeyReverse(w io.Writer, table, parent *table, fk *ForeignKey, funcName string, cols, refs []*column) {
+	fields, goFields := goFields(table, "v")
+	conditions := make([]string, 0, len(cols)+1)
+	args := make([]string, 0, len(refs))
+	for i := range cols {
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quote(cols[i].name)))
+		args = append(args, "parent."+refs[i].rawName)
+	}
+	conditions = append(conditions, readFilters(table)[0].conditions()...)
+
+	sqlSelect := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY %s",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		strings.Join(conditions, " AND "),
+		strings.Join(quoteAll(table.primaryKey.columns), ", "),
+	)
+	fmt.Fprintf(w, "// %s returns the rows of %s that reference parent with the foreign key %s.\n", funcName, quote(table.name), quote(fk.name))
+	fn := goFunc{name: funcName, conn: "queryer", params: []string{"parent *" + m.goTableType(parent)}, results: "([]*" + m.goTableType(table) + ", error)"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "var ret []*%s\n", m.goTableType(table))
+	fmt.Fprintf(w, "%s\n", m.goQuery(":=", table, "queryer", strconv.Quote(sqlSelect), args...))
+	fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return nil, err \n}\n", m.goScanRows(goFields))
+	fmt.Fprintf(w, "ret = append(ret, &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if err := rows.Err(); err != nil {\n return nil, err \n}\n")
+	fmt.Fprintf(w, "return ret, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+func (m *Maker) generateGoTableForeignKeyBatch(w io.Writer, table, parent *table, fk *ForeignKey, funcName string, cols, refs []*column) {
+	// the map key is the values of the referenced columns.
+	// the values of the child rows are converted to the types of the referenced columns.
+	keyTypes := make([]reflect.Type, 0, len(refs))
+	keyExprs := make([]string, 0, len(cols))
+	var nulls []string
+	isPrimaryKey := slices.Equal(fk.references, parent.primaryKey.columns)
+	for i := range cols {
+		typ, _, _ := goValue(refs[i], "")
+		if !typ.Comparable() {
+			// e.g. []byte
+			log.Printf("warning: %s is not generated, because the type of %s.%s can't be a map key", funcName, quote(parent.name), quote(refs[i].name))
+			return
+		}
+		childType, expr, null := goValue(cols[i], "v")
+		if childType != typ {
+			if !isConvertible(childType, typ) {
+				log.Printf("warning: %s is not generated, because the type of %s.%s can't be converted to the type of %s.%s", funcName, quote(table.name), quote(cols[i].name), quote(parent.name), quote(refs[i].name))
+				return
+			}
+			expr = m.imports.typeName(typ) + "(" + expr + ")"
+		}
+		keyTypes = append(keyTypes, typ)
+		keyExprs = append(keyExprs, expr)
+		if null != "" {
+			nulls = append(nulls, null)
+		}
+		if typ != refs[i].rawType {
+			isPrimaryKey = false
+		}
+	}
+	var keyType, key string
+	if len(refs) == 1 {
+		keyType = m.imports.typeName(keyTypes[0])
+		key = keyExprs[0]
+	} else if isPrimaryKey {
+		// reuse the primary key type generated by the map variant of SelectXByPrimaryKeys.
+		keyType = parent.rawName + "PrimaryKey"
+		elements := make([]string, 0, len(refs))
+		for i, c := range refs {
+			elements = append(elements, fmt.Sprintf("%s: %s", c.rawName, keyExprs[i]))
+		}
+		key = keyType + "{" + strings.Join(elements, ", ") + "}"
+	} else {
+		keyType = funcName + "Key"
+		elements := make([]string, 0, len(refs))
+		fmt.Fprintf(w, "// %s is the key of the result of %s.\n", keyType, funcName)
+		fmt.Fprintf(w, "type %s struct {\n", keyType)
+		for i, c := range refs {
+			fmt.Fprintf(w, "%s %s\n", c.rawName, m.imports.typeName(keyTypes[i]))
+			elements = append(elements, fmt.Sprintf("%s: %s", c.rawName, keyExprs[i]))
+		}
+		fmt.Fprintf(w, "}\n\n")
+		key = keyType + "{" + strings.Join(elements, ", ") + "}"
+	}
+
+	// SELECT ... WHERE `parent_id` IN (?, ?, ...)
+	// SELECT ... WHERE (`parent_id1`, `parent_id2`) IN ((?, ?), (?, ?), ...)
+	fields, goFields := goFields(table, "v")
+	quotedCols := make([]string, 0, len(cols))
+	placeholders := make([]string, 0, len(cols))
+	args := make([]string, 0, len(refs))
+	for i := range cols {
+		quotedCols = append(quotedCols, quote(cols[i].name))
+		placeholders = append(placeholders, "?")
+		args = append(args, "parent."+refs[i].rawName)
+	}
+	strCols := quotedCols[0]
+	strPlaceholders := "?"
+	if len(cols) > 1 {
+		strCols = "(" + strings.Join(quotedCols, ", ") + ")"
+		strPlaceholders = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	sqlSelect := fmt.Sprintf(
+		"SELECT %s FROM %s%s IN (",
+		strings.Join(fields, ", "),
+		quote(table.name),
+		whereClause(append(readFilters(table)[0].conditions(), strCols)),
+	)
+	sqlOrder := ") ORDER BY " + strings.Join(quoteAll(table.primaryKey.columns), ", ")
+
+	m.imports.add("strings", "strings")
+	m.noStmtCache(funcName)
+	fmt.Fprintf(w, "// %s returns the rows of %s that reference parents with the foreign key %s.\n", funcName, quote(table.name), quote(fk.name))
+	fmt.Fprintf(w, "// The rows are grouped by the referenced columns of the parents.\n")
+	fmt.Fprintf(w, "// The parents are split into chunks to respect the limit of the placeholders.\n")
+	fn := goFunc{
+		name:    funcName,
+		conn:    "queryer",
+		params:  []string{"parents ...*" + m.goTableType(parent)},
+		results: "(map[" + keyType + "][]*" + m.goTableType(table) + ", error)",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "const chunkSize = %d\n", maxPlaceholderCount/len(cols))
+	fmt.Fprintf(w, "ret := make(map[%s][]*%s, len(parents))\n", keyType, m.goTableType(table))
+	fmt.Fprintf(w, "args := make([]any, 0, min(len(parents), chunkSize)*%d)\n", len(cols))
+	fmt.Fprintf(w, "for len(parents) > 0 {\n")
+	fmt.Fprintf(w, "chunk := parents[:min(len(parents), chunkSize)]\n")
+	fmt.Fprintf(w, "parents = parents[len(chunk):]\n")
+	fmt.Fprintf(w, "args = args[:0]\n")
+	fmt.Fprintf(w, "for _, parent := range chunk {\n")
+	fmt.Fprintf(w, "args = append(args, %s)\n", strings.Join(args, ", "))
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "q := %q + strings.Repeat(%q, len(chunk)-1) + %q\n", sqlSelect+strPlaceholders, ", "+strPlaceholders, sqlOrder)
+	fmt.Fprintf(w, "err := func() error {\n")
+	fmt.Fprintf(w, "%s\n", m.goQuery(":=", table, "queryer", "q", "args..."))
+	fmt.Fprintf(w, "if err != nil {\n return err \n}\n")
+	fmt.Fprintf(w, "defer %s\n", m.goCloseRows())
+	fmt.Fprintf(w, "for %s {\n", m.goNextRow())
+	fmt.Fprintf(w, "var v %s\n", m.goTableType(table))
+	fmt.Fprintf(w, "if err := %s; err != nil {\n return err \n}\n", m.goScanRows(goFields))
+	if len(nulls) > 0 {
+		// NULL never matches the referenced columns, but the compiler doesn't know it.
+		fmt.Fprintf(w, "if %s {\n continue \n}\n", strings.Join(nulls, " || "))
+	}
+	fmt.Fprintf(w, "key := %s\n", key)
+	fmt.Fprintf(w, "ret[key] = append(ret[key], &v)\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return rows.Err()\n")
+	fmt.Fprintf(w, "}()\n")
+	fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return ret, nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+// goValue returns the type of the non-NULL value of the column c,
+// the Go expression of the value in the struct v, and the condition that the value is NULL.
+// The condition is empty if the field can't be NULL.
+func goValue(c *column, v string) (reflect.Type, string, string) {
+	return goExprValue(c.fieldType, v+"."+c.rawName)
+}
+
+// goExprValue is same as goValue, but it takes the Go expression field of the type typ.
+func goExprValue(typ reflect.Type, field string) (reflect.Type, string, string) {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem(), "*" + field, field + " == nil"
+	}
+	if typ.PkgPath() == "database/sql" && typ.Kind() == reflect.Struct && typ.NumField() == 2 && typ.Field(1).Name == "Valid" {
+		// sql.NullInt64, sql.Null[T], etc.
+		return typ.Field(0).Type, field + "." + typ.Field(0).Name, "!" + field + ".Valid"
+	}
+	return typ, field, ""
+}
+
+// isConvertible reports whether the Go value of from can be converted to to without loss of meaning,
+// i.e. both are integers or both are strings.
+func isConvertible(from, to reflect.Type) bool {
+	if isIntegerKind(from.Kind()) && isIntegerKind(to.Kind()) {
+		return true
+	}
+	return from.Kind() == reflect.String && to.Kind() == reflect.String
+}
+
+func (m *Maker) generateGoTableUpdate(w io.Writer, table *table) {
+	setFields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	params := make([]string, 0, len(table.primaryKey.columns))
+	conditions := make([]string, 0, len(table.primaryKey.columns))
+	version := table.versionColumn()
+	created, updated := table.createdColumn(), table.updatedColumn()
+
+LOOP:
+	for _, c := range table.columns {
+		for _, key := range table.primaryKey.columns {
+			if key == c.name {
+				params = append(params, fmt.Sprintf("value.%s", c.rawName))
+				conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
+				continue LOOP
+			}
+		}
+		if c == version || c == created {
+			continue
+		}
+		setFields = append(setFields, fmt.Sprintf("%s = ?", quote(c.name)))
+		goFields = append(goFields, "value."+c.rawName)
+	}
+	args := append(slices.Clip(goFields), params...)
+	if version != nil {
+		setFields = append(setFields, fmt.Sprintf("%[1]s = %[1]s + 1", quote(version.name)))
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quote(version.name)))
+		args = append(args, "value."+version.rawName)
+	}
+
+	update := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s",
+		quote(table.name),
+		strings.Join(setFields, ", "),
+		strings.Join(conditions, " AND "),
+	)
+	if version != nil {
+		fmt.Fprintf(w, "// Update%[1]s updates the rows, and increments their %[2]s fields.\n", table.rawName, version.rawName)
+		fmt.Fprintf(w, "// It returns *ErrStaleObject if the row has been modified or deleted by others.\n")
+		fmt.Fprintf(w, "// The values before the stale one have been already updated, and their %s fields have been incremented,\n", version.rawName)
+		fmt.Fprintf(w, "// so call it in a transaction if the values must be updated all or nothing.\n")
+	} else if updated != nil || created != nil {
+		fmt.Fprintf(w, "// Update%s updates the rows.\n", table.rawName)
+	}
+	if updated != nil {
+		fmt.Fprintf(w, "// It sets the %s fields to the current time returned by Now.\n", updated.rawName)
+	}
+	if created != nil {
+		fmt.Fprintf(w, "// The %s column is not updated.\n", quote(created.name))
+	}
+	fn := goFunc{name: "Update" + table.rawName, conn: "execer", params: []string{"values ...*" + m.goTableType(table)}, results: "error"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	if len(setFields) != 0 {
+		fmt.Fprintf(w, "stmt, err := %s\n", m.goPrepare(table, `"update"`, "execer", strconv.Quote(update)))
+		fmt.Fprintf(w, "if err != nil {\n")
+		fmt.Fprintf(w, "return err\n")
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "defer %s\n", m.goCloseStmt("execer"))
+		if updated != nil {
+			fmt.Fprintf(w, "now := Now()\n")
+		}
+		fmt.Fprintf(w, "for _, value := range values {\n")
+		m.generateGoSetTimestamps(w, "value", updated)
+		if version == nil {
+			fmt.Fprintf(w, "if _, err := %s; err != nil {\n", m.goExec(table, `"update"`, "stmt", strconv.Quote(update), args...))
+			fmt.Fprintf(w, "return err\n")
+			fmt.Fprintf(w, "}\n")
+		} else {
+			fmt.Fprintf(w, "result, err := %s\n", m.goExec(table, `"update"`, "stmt", strconv.Quote(update), args...))
+			fmt.Fprintf(w, "if err != nil {\n return err \n}\n")
+			m.generateGoCheckStale(w, table, "result", params)
+			fmt.Fprintf(w, "value.%s++\n", version.rawName)
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "return nil\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+// generateGoCheckStale generates the Go code that returns *ErrStaleObject if no row is affected.
+// keys are the Go expressions of the primary key.
+func (m *Maker) generateGoCheckStale(w io.Writer, table *table, result string, keys []string) {
+	fmt.Fprintf(w, "if n, err := %s.RowsAffected(); err != nil {\n", result)
+	fmt.Fprintf(w, "return err\n")
+	fmt.Fprintf(w, "} else if n == 0 {\n")
+	fmt.Fprintf(w, "return &ErrStaleObject{Table: %q, PrimaryKey: []any{%s}}\n", table.name, strings.Join(keys, ", "))
+	fmt.Fprintf(w, "}\n")
+}
+
+// generateGoErrStaleObject generates ErrStaleObject if some tables have the version columns.
+func (m *Maker) generateGoErrStaleObject(w io.Writer) {
+	if !slices.ContainsFunc(m.tables, func(t *table) bool { return t.versionColumn() != nil }) {
+		return
+	}
+	m.imports.add("fmt", "fmt")
+	fmt.Fprintf(w, `// ErrStaleObject is the error returned when the row to update has been modified or deleted by others.
+type ErrStaleObject struct {
+	// Table is the name of the table.
+	Table string
+
+	// PrimaryKey is the values of the primary key.
+	PrimaryKey []any
+}
+
+func (e *ErrStaleObject) Error() string {
+	return fmt.Sprintf("%s: stale object: table %%s, primary key %%v", e.Table, e.PrimaryKey)
+}
+
+`, m.config.PackageName)
+}
+
+func (m *Maker) generateGoNow(w io.Writer) {
+	if !slices.ContainsFunc(m.tables, func(t *table) bool {
+		return t.createdColumn() != nil || t.updatedColumn() != nil || t.softDeleteColumn() != nil
+	}) {
+		return
+	}
+	m.imports.add("time", "time")
+	fmt.Fprintf(w, "// Now returns the current time.\n")
+	fmt.Fprintf(w, "// It is used to set the created and updated columns and the soft delete columns of FakeDB, and can be replaced in tests.\n")
+	fmt.Fprintf(w, "var Now = time.Now\n\n")
+}
+
+// generateGoSetTimestamps generates the code that sets the timestamp columns cols of v to the time now.
+// The time is truncated to the fractional seconds precision of the columns,
+// so the values of the fields are same as the values stored in the database.
+func (m *Maker) generateGoSetTimestamps(w io.Writer, v string, cols ...*column) {
+	for _, c := range cols {
+		if c == nil {
+			continue
+		}
+		m.imports.add("time", "time")
+		fmt.Fprintf(w, "%s.%s = now.Truncate(%s)\n", v, c.rawName, goFractionalSeconds(c.size))
+	}
+}
+
+// goFractionalSeconds returns the Go expression of the time.Duration of the fractional seconds precision fsp.
+func goFractionalSeconds(fsp int) string {
+	switch fsp {
+	case 1:
+		return "100 * time.Millisecond"
+	case 2:
+		return "10 * time.Millisecond"
+	case 3:
+		return "time.Millisecond"
+	case 4:
+		return "100 * time.Microsecond"
+	case 5:
+		return "10 * time.Microsecond"
+	case 6:
+		return "time.Microsecond"
+	}
+	return "time.Second"
+}
+
+func (m *Maker) generateGoTableDelete(w io.Writer, table *table) {
+	keys := make([]string, 0, len(table.primaryKey.columns))
+	placeholders := make([]string, 0, len(table.primaryKey.columns))
+	params := make([]string, 0, len(table.primaryKey.columns))
+	for _, key := range table.primaryKey.columns {
+		for _, c := range table.columns {
+			if key == c.name {
+				keys = append(keys, quote(c.name))
+				placeholders = append(placeholders, "?")
+				params = append(params, "v."+c.rawName)
+			}
+		}
+	}
+
+	// DELETE FROM `table` WHERE `id` IN (?, ?, ...)
+	// DELETE FROM `table` WHERE (`id1`, `id2`) IN ((?, ?), (?, ?), ...)
+	strKeys := keys[0]
+	strPlaceholders := "?"
+	if len(keys) > 1 {
+		strKeys = "(" + strings.Join(keys, ", ") + ")"
+		strPlaceholders = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	del := "DELETE FROM " + quote(table.name) + " WHERE " + strKeys + " IN (" + strPlaceholders
+
+	col := table.softDeleteColumn()
+	if col == nil {
+		fmt.Fprintf(w, "// Delete%s deletes the rows from %s by the primary key.\n", table.rawName, quote(table.name))
+		m.generateGoTableDeleteFunc(w, table, "Delete", del, strPlaceholders, params)
+		return
+	}
+
+	// UPDATE `table` SET `deleted_at` = CURRENT_TIMESTAMP(6) WHERE `deleted_at` IS NULL AND `id` IN (?, ?, ...)
+	now := "CURRENT_TIMESTAMP"
+	if col.size > 0 {
+		now = fmt.Sprintf("CURRENT_TIMESTAMP(%d)", col.size)
+	}
+	softDel := fmt.Sprintf(
+		"UPDATE %s SET %s = %s WHERE %s IS NULL AND %s IN (%s",
+		quote(table.name),
+		quote(col.name),
+		now,
+		quote(col.name),
+		strKeys,
+		strPlaceholders,
+	)
+	fmt.Fprintf(w, "// Delete%s sets %s of the rows to the current time instead of deleting them.\n", table.rawName, quote(col.name))
+	fmt.Fprintf(w, "// The rows that have been already deleted are not changed.\n")
+	m.generateGoTableDeleteFunc(w, table, "Delete", softDel, strPlaceholders, params)
+	fmt.Fprintf(w, "// HardDelete%s deletes the rows from %s by the primary key, including the soft-deleted rows.\n", table.rawName, quote(table.name))
+	m.generateGoTableDeleteFunc(w, table, "HardDelete", del, strPlaceholders, params)
+}
+
+// generateGoTableDeleteFunc generates the function named funcName that executes the statement del for each batch of the values.
+// The statement del must end with the first placeholder strPlaceholders.
+// The caller writes the doc comment of the function funcName.
+func (m *Maker) generateGoTableDeleteFunc(w io.Writer, table *table, funcName, del, strPlaceholders string, params []string) {
+	maxStructCount := structCountPerStatement(len(params))
+	strPlaceholders = ", " + strPlaceholders
+
+	fn := goFunc{name: funcName + table.rawName, conn: "execer", params: []string{"values ...*" + m.goTableType(table)}, results: "error"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "_, err := %[2]s%[1]sRowsAffected(ctx, execer, values...)\n", table.rawName, funcName)
+	fmt.Fprintf(w, "return err\n")
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+
+	fn = goFunc{name: funcName + table.rawName + "RowsAffected", conn: "execer", params: []string{"values ...*" + m.goTableType(table)}, results: "(int64, error)"}
+	fmt.Fprintf(w, "// %[1]sRowsAffected is same as %[1]s, but it also returns the number of the rows affected.\n", funcName+table.rawName)
+	fmt.Fprintf(w, "// It counts only the rows that actually matched; the values that are not found in the table are not counted.\n")
+	fmt.Fprintf(w, "func %s {", fn)
+	fmt.Fprintf(w, "const q = %q+\n%q\n", del, strings.Repeat(strPlaceholders, maxStructCount-1)+")")
+	fmt.Fprintf(w, "const fieldCount = %d\n", len(params))
+	fmt.Fprintf(w, "const maxStructCount = %d\n", maxStructCount)
+
+	fmt.Fprintf(w, `var rowsAffected int64
+	var args []any
+	if len(values) >= maxStructCount {
+		args = make([]any, 0, maxStructCount*fieldCount)
+		err := func() error {
+			stmt, err := %[7]s
+			if err != nil {
+				return err
+			}
+			defer %[4]s
+
+			for len(values) >= maxStructCount {
+				vals, rest := values[:maxStructCount], values[maxStructCount:]
+				args = args[:0]
+				for _, v := range vals {
+					args = append(args, %[1]s)
+				}
+				result, err := %[5]s
+				if err != nil {
+					return err
+				}
+				n, err := result.RowsAffected()
+				if err != nil {
+					return err
+				}
+				rowsAffected += n
+				values = rest
+			}
+			return nil
+		}()
+		if err != nil {
+			return rowsAffected, err
+		}
+	}
+	if len(values) == 0 {
+		return rowsAffected, nil
+	}
+	if len(args) == 0 {
+		args = make([]any, 0, len(values)*fieldCount)
+	}
+	args = args[:0]
+	for _, v := range values {
+		args = append(args, %[1]s)
+	}
+	result, err := %[6]s
+	if err != nil {
+		return rowsAffected, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return rowsAffected, err
+	}
+	return rowsAffected + n, nil
+}
+
+`, strings.Join(params, ", "), len(strPlaceholders), len(del)-len(strPlaceholders), m.goCloseStmt("execer"),
+		m.goExec(table, `"delete"`, "stmt", "q", "args..."),
+		m.goExec(table, `"delete"`, "execer", fmt.Sprintf(`q[:len(values)*%d+%d]+")"`, len(strPlaceholders), len(del)-len(strPlaceholders)), "args..."),
+		m.goPrepare(table, `"delete"`, "execer", "q"))
+	m.generateGoQueriesMethod(w, fn)
+}
+
+func (m *Maker) generateGoTableUpsert(w io.Writer, table *table) {
+	isPrimaryKey := map[string]bool{}
+	for _, key := range table.primaryKey.columns {
+		isPrimaryKey[key] = true
+	}
+
+	// the assignments in ON DUPLICATE KEY UPDATE clause.
+	// the version column is incremented instead of being overwritten.
+	var prefix string
+	version := table.versionColumn()
+	assignment := func(col string) string {
+		if version != nil && version.name == col {
+			return fmt.Sprintf("%[1]s = %[1]s + 1", quote(col))
+		}
+		if m.config.UpsertRowAlias {
+			return fmt.Sprintf("%s = `new`.%s", quote(col), quote(col))
+		}
+		return fmt.Sprintf("%s = VALUES(%s)", quote(col), quote(col))
+	}
+	if m.config.UpsertRowAlias {
+		prefix = " AS `new`"
+	}
+	prefix += " ON DUPLICATE KEY UPDATE "
+
+	// the columns that can be updated.
+	// the created column keeps the time when the row is inserted,
+	// and the soft delete column is kept so that upserts don't restore the deleted rows.
+	created, updated := table.createdColumn(), table.updatedColumn()
+	softDelete := table.softDeleteColumn()
+	var columns []*column
+	var defaultAssignments []string
+	for _, c := range table.columns {
+		if c.autoIncr || c == created || c == softDelete {
+			continue
+		}
+		columns = append(columns, c)
+		if !isPrimaryKey[c.name] {
+			defaultAssignments = append(defaultAssignments, assignment(c.name))
+		}
+	}
+
+	// if there is no column to update, assign the primary key to itself.
+	// it is no-op, so the existing rows are kept as they are.
+	noop := fmt.Sprintf("%s = %s", quote(table.primaryKey.columns[0]), quote(table.primaryKey.columns[0]))
+	if len(defaultAssignments) == 0 {
+		defaultAssignments = append(defaultAssignments, noop)
+	}
+
+	// the auto increment columns are inserted with the values of the fields,
+	// so the existing rows are matched by them. MySQL generates the IDs for the zero values.
+	insert := "insert"
+	autoIdx := slices.IndexFunc(table.columns, func(c *column) bool { return c.autoIncr })
+	if autoIdx >= 0 {
+		insert = "upsert"
+		m.generateGoTableInsertHelper(w, table, insert, nil, true)
+	}
+
+	fmt.Fprintf(w, "// Upsert%[1]s inserts the values, or updates the rows if they already exist.\n", table.rawName)
+	if autoIdx >= 0 {
+		auto := table.columns[autoIdx]
+		fmt.Fprintf(w, "// The %[1]s fields are inserted as they are, so the rows with the same %[1]s are updated.\n", auto.rawName)
+		fmt.Fprintf(w, "// The rows with zero %[1]s are inserted with the IDs generated by MySQL, but the IDs are not assigned to the values.\n", auto.rawName)
+	}
+	if created != nil {
+		fmt.Fprintf(w, "// The %[1]s column of the existing rows is not updated.\n", quote(created.name))
+		fmt.Fprintf(w, "// It sets the %[1]s fields only if they are zero, so set them to the stored values to keep them in sync.\n", created.rawName)
+	}
+	if softDelete != nil {
+		fmt.Fprintf(w, "// The %[1]s column of the existing rows is not updated, so the soft-deleted rows are kept deleted.\n", quote(softDelete.name))
+	}
+	fn := goFunc{name: "Upsert" + table.rawName, conn: "execer", params: []string{"values ...*" + m.goTableType(table)}, results: "error"}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "return %[1]s%[2]s(ctx, execer, %[3]q, false, values...)\n", insert, table.rawName, prefix+strings.Join(defaultAssignments, ", "))
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+
+	fmt.Fprintf(w, "// Upsert%[1]sColumns is same as Upsert%[1]s, but it updates only the columns in columns if the rows already exist.\n", table.rawName)
+	fmt.Fprintf(w, "// If columns is empty, the existing rows are kept as they are.\n")
+	if updated != nil {
+		fmt.Fprintf(w, "// The %s column is always updated with the other columns.\n", quote(updated.name))
+	}
+	m.noStmtCache("Upsert" + table.rawName + "Columns")
+	fn = goFunc{
+		name:    "Upsert" + table.rawName + "Columns",
+		conn:    "execer",
+		params:  []string{"columns []" + table.rawName + "Column", "values ...*" + m.goTableType(table)},
+		results: "error",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "suffix := %q\n", prefix)
+	fmt.Fprintf(w, "if len(columns) == 0 {\n")
+	fmt.Fprintf(w, "suffix += %q\n", noop)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "for i, col := range columns {\n")
+	fmt.Fprintf(w, "if i > 0 {\n")
+	fmt.Fprintf(w, "suffix += \", \"\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "switch col {\n")
+	for _, c := range columns {
+		fmt.Fprintf(w, "case %s:\n", goColumnName(table, c))
+		fmt.Fprintf(w, "suffix += %q\n", assignment(c.name))
+	}
+	m.imports.add("fmt", "fmt")
+	fmt.Fprintf(w, "default:\n")
+	fmt.Fprintf(w, "return fmt.Errorf(\"%s: unknown column of %s: %%q\", col)\n", m.config.PackageName, table.name)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "}\n")
+	if updated != nil {
+		// the updated column is always updated with the other columns.
+		m.imports.add("slices", "slices")
+		fmt.Fprintf(w, "if len(columns) > 0 && !slices.Contains(columns, %s) {\n", goColumnName(table, updated))
+		fmt.Fprintf(w, "suffix += %q\n", ", "+assignment(updated.name))
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "return %[1]s%[2]s(ctx, execer, suffix, false, values...)\n", insert, table.rawName)
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+func (m *Maker) generateGoTableUpdateColumns(w io.Writer, table *table) {
+	m.imports.add("fmt", "fmt")
+	m.imports.add("strings", "strings")
+
+	isPrimaryKey := map[string]bool{}
+	for _, key := range table.primaryKey.columns {
+		isPrimaryKey[key] = true
+	}
+	var params, conditions []string
+	for _, key := range table.primaryKey.columns {
+		for _, c := range table.columns {
+			if c.name == key {
+				params = append(params, "v."+c.rawName)
+				conditions = append(conditions, fmt.Sprintf("%s = ?", quote(c.name)))
+			}
+		}
+	}
+
+	version := table.versionColumn()
+	created, updated := table.createdColumn(), table.updatedColumn()
+	fmt.Fprintf(w, "// Update%[1]sColumns updates only the columns of v specified by cols.\n", table.rawName)
+	fmt.Fprintf(w, "// The row is identified by the primary key of v. The primary key columns can't be updated.\n")
+	if version != nil {
+		fmt.Fprintf(w, "// It increments the %s field, and returns *ErrStaleObject if the row has been modified or deleted by others.\n", version.rawName)
+	}
+	if created != nil {
+		fmt.Fprintf(w, "// The %s column can't be updated.\n", quote(created.name))
+	}
+	if updated != nil {
+		fmt.Fprintf(w, "// It always sets the %s field to the current time returned by Now.\n", updated.rawName)
+	}
+	m.noStmtCache("Update" + table.rawName + "Columns")
+	fn := goFunc{
+		name:    "Update" + table.rawName + "Columns",
+		conn:    "execer",
+		params:  []string{"v *" + m.goTableType(table), "cols ..." + table.rawName + "Column"},
+		results: "error",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "if len(cols) == 0 {\n return nil \n}\n")
+	fmt.Fprintf(w, "sets := make([]string, 0, len(cols))\n")
+	fmt.Fprintf(w, "args := make([]any, 0, len(cols)+%d)\n", len(params))
+	fmt.Fprintf(w, "for _, col := range cols {\n")
+	fmt.Fprintf(w, "switch col {\n")
+	for _, c := range table.columns {
+		if isPrimaryKey[c.name] || c == version || c == created {
+			continue
+		}
+		if c == updated {
+			fmt.Fprintf(w, "// the updated column is always set below.\n")
+			fmt.Fprintf(w, "case %s:\n", goColumnName(table, c))
+			continue
+		}
+		fmt.Fprintf(w, "case %s:\n", goColumnName(table, c))
+		fmt.Fprintf(w, "sets = append(sets, %q)\n", fmt.Sprintf("%s = ?", quote(c.name)))
+		fmt.Fprintf(w, "args = append(args, v.%s)\n", c.rawName)
+	}
+	fmt.Fprintf(w, "default:\n")
+	fmt.Fprintf(w, "return fmt.Errorf(\"%s: can't update the column of %s: %%q\", col)\n", m.config.PackageName, table.name)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "}\n")
+	if updated != nil {
+		fmt.Fprintf(w, "now := Now()\n")
+		m.generateGoSetTimestamps(w, "v", updated)
+		fmt.Fprintf(w, "sets = append(sets, %q)\n", fmt.Sprintf("%s = ?", quote(updated.name)))
+		fmt.Fprintf(w, "args = append(args, v.%s)\n", updated.rawName)
+	}
+	if version != nil {
+		fmt.Fprintf(w, "sets = append(sets, %q)\n", fmt.Sprintf("%[1]s = %[1]s + 1", quote(version.name)))
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quote(version.name)))
+		params = append(params, "v."+version.rawName)
+	}
+	fmt.Fprintf(w, "args = append(args, %s)\n", strings.Join(params, ", "))
+	fmt.Fprintf(w, "q := %q + strings.Join(sets, \", \") + %q\n", "UPDATE "+quote(table.name)+" SET ", " WHERE "+strings.Join(conditions, " AND "))
+	if version == nil {
+		fmt.Fprintf(w, "_, err := %s\n", m.goExec(table, `"update"`, "execer", "q", "args..."))
+		fmt.Fprintf(w, "return err\n")
+	} else {
+		fmt.Fprintf(w, "result, err := %s\n", m.goExec(table, `"update"`, "execer", "q", "args..."))
+		fmt.Fprintf(w, "if err != nil {\n return err \n}\n")
+		m.generateGoCheckStale(w, table, "result", params[:len(params)-1])
+		fmt.Fprintf(w, "v.%s++\n", version.rawName)
+		fmt.Fprintf(w, "return nil\n")
+	}
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+
+	fmt.Fprintf(w, "// Update%[1]sDiff updates only the columns that are changed from original to modified.\n", table.rawName)
+	fmt.Fprintf(w, "// The row is identified by the primary key of modified.\n")
+	m.noStmtCache("Update" + table.rawName + "Diff")
+	fn = goFunc{
+		name:    "Update" + table.rawName + "Diff",
+		conn:    "execer",
+		params:  []string{"original, modified *" + m.goTableType(table)},
+		results: "error",
+	}
+	fmt.Fprintf(w, "func %s {\n", fn)
+	fmt.Fprintf(w, "var cols []%sColumn\n", table.rawName)
+	for _, c := range table.columns {
+		if isPrimaryKey[c.name] || c == version || c == created || c == updated {
+			continue
+		}
+		fmt.Fprintf(w, "if %s {\n", m.goNotEqual(c.fieldType, "original."+c.rawName, "modified."+c.rawName))
+		fmt.Fprintf(w, "cols = append(cols, %s)\n", goColumnName(table, c))
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "return Update%sColumns(ctx, execer, modified, cols...)\n", table.rawName)
+	fmt.Fprintf(w, "}\n\n")
+	m.generateGoQueriesMethod(w, fn)
+}
+
+// goNotEqual returns the Go expression that reports whether a and b of typ are different.
+func (m *Maker) goNotEqual(typ reflect.Type, a, b string) string {
+	switch {
+	case typ == timeType:
+		return fmt.Sprintf("!%s.Equal(%s)", a, b)
+	case typ.Kind() == reflect.Pointer:
+		elem := m.goNotEqual(typ.Elem(), "(*"+a+")", "(*"+b+")")
+		return fmt.Sprintf("(%[1]s == nil) != (%[2]s == nil) || (%[1]s != nil && %[3]s)", a, b, elem)
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		m.imports.add("bytes", "bytes")
+		return fmt.Sprintf("!bytes.Equal(%s, %s)", a, b)
+	case typ.Comparable():
+		return fmt.Sprintf("%s != %s", a, b)
+	}
+	m.imports.add("reflect", "reflect")
+	return fmt.Sprintf("!reflect.DeepEqual(%s, %s)", a, b)
+}
+
+// ptrInt returns a pointer to int value.
+func ptrInt(v int) *int {
+	return &v
+}
+
+// valInt returns a value of int pointer.
+func valInt(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// goFunc is the signature of a generated function that takes execer or queryer.
+type goFunc struct {
+	name string
+
+	// conn is the type of the second parameter, "execer" or "queryer".
+	conn string
+
+	// params is the parameters after conn, e.g. "values ...*User" or "original, modified *User".
+	params []string
+
+	// results is the results, e.g. "(*User, error)".
+	results string
+}
+
+// String returns the signature of f without the func keyword.
+func (f goFunc) String() string {
+	params := append([]string{"ctx context.Context", f.conn + " " + f.conn}, f.params...)
+	return fmt.Sprintf("%s(%s) %s", f.name, strings.Join(params, ", "), f.results)
+}
+
+// generateGoQueriesMethod generates the method of Queries that calls the function f.
+func (m *Maker) generateGoQueriesMethod(w io.Writer, f goFunc) {
+	if !m.config.GenerateQueries {
+		return
+	}
+
+	conn := "q"
+	if m.uncachedFuncs[f.name] {
+		conn = "q.conn()"
+	} else if m.config.GenerateSQLCommenter {
+		conn = "q.cached()"
+	}
+	args := []string{"ctx", conn}
+	for _, param := range f.params {
+		// param is "name type" or "name1, name2 type".
+		i := strings.LastIndex(param, " ")
+		names, typ := param[:i], param[i+1:]
+		for _, name := range strings.Split(names, ", ") {
+			if strings.HasPrefix(typ, "...") {
+				name += "..."
+			}
+			args = append(args, name)
+		}
+	}
+
+	switch conn {
+	case "q":
+		fmt.Fprintf(w, "// %[1]s calls %[1]s with the cached statements.\n", f.name)
+	case "q.cached()":
+		fmt.Fprintf(w, "// %[1]s calls %[1]s with the cached statements, unless the commenter is set.\n", f.name)
+	default:
+		fmt.Fprintf(w, "// %[1]s calls %[1]s without the cache, because it builds the queries at runtime.\n", f.name)
+	}
+	fmt.Fprintf(w, "func (q *Queries) %s(%s) %s {\n", f.name, strings.Join(append([]string{"ctx context.Context"}, f.params...), ", "), f.results)
+	fmt.Fprintf(w, "return %s(%s)\n", f.name, strings.Join(args, ", "))
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// noStmtCache marks the generated function name builds the queries at runtime.
+// The number of the queries may be large, so the methods of Queries don't cache them.
+func (m *Maker) noStmtCache(name string) {
+	m.uncachedFuncs[name] = true
+}
+
+// goCloseStmt returns the Go code that closes the statement stmt prepared by execer.
+func (m *Maker) goCloseStmt(execer string) string {
+	if !m.config.GenerateQueries {
+		return "stmt.Close()"
+	}
+	return fmt.Sprintf("closeStmt(%s, stmt)", execer)
+}
+
+// goExec returns the Go expression that executes query by execer with args.
+// If execer is "stmt", it executes the statement prepared from query.
+// operation is the Go expression of the operation name passed to the hooks.
+func (m *Maker) goExec(table *table, operation, execer, query string, args ...string) string {
+	query = m.goAnnotate(table, operation, query)
+	if !m.config.GenerateHooks {
+		if execer == "stmt" {
+			return fmt.Sprintf("stmt.ExecContext(%s)", strings.Join(append([]string{"ctx"}, args...), ", "))
+		}
+		return fmt.Sprintf("%s.ExecContext(%s)", execer, strings.Join(append([]string{"ctx", query}, args...), ", "))
+	}
+	if execer == "stmt" {
+		return fmt.Sprintf("stmtExecWithHooks(%s)", strings.Join(append([]string{"ctx", "stmt", strconv.Quote(table.name), operation, query}, args...), ", "))
+	}
+	return fmt.Sprintf("execWithHooks(%s)", strings.Join(append([]string{"ctx", execer, strconv.Quote(table.name), operation, query}, args...), ", "))
+}
+
+// goPrepare returns the Go expression that prepares query by execer.
+func (m *Maker) goPrepare(table *table, operation, execer, query string) string {
+	return fmt.Sprintf("%s.PrepareContext(ctx, %s)", execer, m.goAnnotate(table, operation, query))
+}
+
+// goAnnotate returns the Go expression that prefixes the SQL comment to query.
+func (m *Maker) goAnnotate(table *table, operation, query string) string {
+	if !m.config.GenerateSQLCommenter {
+		return query
+	}
+	return fmt.Sprintf("annotate(ctx, %q, %s, %s)", table.name, operation, query)
+}
+
+// goQuery returns the Go statement that executes the SELECT statement query by queryer with args,
+// and assigns the results to rows and err with the assignment operator assign.
+// If the hooks are enabled, it also assigns the running hook to hook.
+func (m *Maker) goQuery(assign string, table *table, queryer, query string, args ...string) string {
+	query = m.goAnnotate(table, `"select"`, query)
+	if !m.config.GenerateHooks {
+		return fmt.Sprintf("rows, err %s %s.QueryContext(%s)", assign, queryer, strings.Join(append([]string{"ctx", query}, args...), ", "))
+	}
+	return fmt.Sprintf("rows, hook, err %s queryWithHooks(%s)", assign, strings.Join(append([]string{"ctx", queryer, strconv.Quote(table.name), query}, args...), ", "))
+}
+
+// goQueryRow is same as goQuery, but it assigns the result to row.
+// With Config.GenerateQueries, the query is issued by queryRow,
+// because *sql.Row can't report the error of preparing the cached statement.
+func (m *Maker) goQueryRow(table *table, queryer, query string, args ...string) string {
+	query = m.goAnnotate(table, `"select"`, query)
+	if !m.config.GenerateHooks {
+		if m.config.GenerateQueries {
+			return fmt.Sprintf("row := queryRow(%s)", strings.Join(append([]string{"ctx", queryer, query}, args...), ", "))
+		}
+		return fmt.Sprintf("row := %s.QueryRowContext(%s)", queryer, strings.Join(append([]string{"ctx", query}, args...), ", "))
+	}
+	return fmt.Sprintf("row, hook := queryRowWithHooks(%s)", strings.Join(append([]string{"ctx", queryer, strconv.Quote(table.name), query}, args...), ", "))
+}
+
+// goScanRow returns the Go expression that scans row into dest.
+func (m *Maker) goScanRow(dest []string) string {
+	if !m.config.GenerateHooks {
+		return fmt.Sprintf("row.Scan(%s)", strings.Join(dest, ", "))
+	}
+	return fmt.Sprintf("hook.scan(%s)", strings.Join(append([]string{"row"}, dest...), ", "))
+}
+
+// goNextRow returns the Go expression that advances rows.
+func (m *Maker) goNextRow() string {
+	if !m.config.GenerateHooks {
+		return "rows.Next()"
+	}
+	return "hook.next(rows)"
+}
+
+// goScanRows returns the Go expression that scans the current row of rows into dest.
+func (m *Maker) goScanRows(dest []string) string {
+	if !m.config.GenerateHooks {
+		return fmt.Sprintf("rows.Scan(%s)", strings.Join(dest, ", "))
+	}
+	return fmt.Sprintf("hook.scanRows(%s)", strings.Join(append([]string{"rows"}, dest...), ", "))
+}
+
+// goCloseRows returns the Go expression that closes rows.
+func (m *Maker) goCloseRows() string {
+	if !m.config.GenerateHooks {
+		return "rows.Close()"
+	}
+	return "hook.close(rows)"
+}
+
+// generateGoDeclareHook declares the variable hook assigned by goQuery.
+func (m *Maker) generateGoDeclareHook(w io.Writer) {
+	if !m.config.GenerateHooks {
+		return
+	}
+	fmt.Fprintf(w, "var hook *queryHook\n")
+}
+
+func (m *Maker) generateGoHooks(w io.Writer) {
+	if !m.config.GenerateHooks || len(m.tables) == 0 {
+		return
+	}
+	io.WriteString(w, `// QueryInfo is the information about a query issued by the generated functions.
+type QueryInfo struct {
+	// Table is the name of the table.
+	Table string
+
+	// Operation is one of "select", "insert", "update", "delete" and "upsert".
+	Operation string
+
+	// Query is the SQL statement.
+	Query string
+
+	// RowCount is the number of the rows affected by the statement,
+	// or the number of the rows read from the result of SELECT statement.
+	// It is set only in Hooks.After.
+	RowCount int64
+}
+
+// Hooks is called around the queries issued by the generated functions.
+type Hooks interface {
+	// Before is called before the query is executed.
+	// The returned context is used for the query and passed to After.
+	Before(ctx context.Context, info QueryInfo) context.Context
+
+	// After is called after the query is executed.
+	// For SELECT statements, it is called after the rows are read.
+	After(ctx context.Context, info QueryInfo, err error)
+}
+
+var hooks Hooks
+
+// SetHooks sets the hooks called around the queries.
+// It is not safe to call SetHooks concurrently with the queries,
+// so call it in the initialization of the program.
+func SetHooks(h Hooks) {
+	hooks = h
+}
+
+// queryHook is the hooks of a running query.
+// The methods of nil *queryHook just run the query without hooks.
+type queryHook struct {
+	hooks Hooks
+	ctx   context.Context
+	info  QueryInfo
+
+	// err is the error of scanning the rows.
+	err error
+}
+
+func beginQuery(ctx context.Context, table, operation, query string) (context.Context, *queryHook) {
+	if hooks == nil {
+		return ctx, nil
+	}
+	hook := &queryHook{
+		hooks: hooks,
+		info:  QueryInfo{Table: table, Operation: operation, Query: query},
+	}
+	hook.ctx = hooks.Before(ctx, hook.info)
+	return hook.ctx, hook
+}
+
+func (h *queryHook) end(err error) {
+	if h == nil {
+		return
+	}
+	h.hooks.After(h.ctx, h.info, err)
+}
+
+func (h *queryHook) endExec(result sql.Result, err error) {
+	if h == nil {
+		return
+	}
+	if err == nil {
+		if n, err := result.RowsAffected(); err == nil {
+			h.info.RowCount = n
+		}
+	}
+	h.end(err)
+}
+
+// next advances rows, and counts the rows.
+func (h *queryHook) next(rows *sql.Rows) bool {
+	if !rows.Next() {
+		return false
+	}
+	if h != nil {
+		h.info.RowCount++
+	}
+	return true
+}
+
+// scanRows copies the columns of the current row of rows into dest.
+// The error is passed to the hook by close.
+func (h *queryHook) scanRows(rows *sql.Rows, dest ...any) error {
+	err := rows.Scan(dest...)
+	if h != nil && err != nil && h.err == nil {
+		h.err = err
+	}
+	return err
+}
+
+// close closes rows, and ends the hook.
+func (h *queryHook) close(rows *sql.Rows) error {
+	err := rows.Close()
+	if h != nil && h.err != nil {
+		h.end(h.err)
+	} else {
+		h.end(rows.Err())
+	}
+	return err
+}
+
+// scan copies the columns of row into dest, and ends the hook.
+func (h *queryHook) scan(row interface{ Scan(dest ...any) error }, dest ...any) error {
+	err := row.Scan(dest...)
+	if h != nil && err == nil {
+		h.info.RowCount = 1
+	}
+	h.end(err)
+	return err
+}
+
+func execWithHooks(ctx context.Context, execer execer, table, operation, query string, args ...any) (sql.Result, error) {
+	ctx, hook := beginQuery(ctx, table, operation, query)
+	result, err := execer.ExecContext(ctx, query, args...)
+	hook.endExec(result, err)
+	return result, err
+}
+
+func stmtExecWithHooks(ctx context.Context, stmt *sql.Stmt, table, operation, query string, args ...any) (sql.Result, error) {
+	ctx, hook := beginQuery(ctx, table, operation, query)
+	result, err := stmt.ExecContext(ctx, args...)
+	hook.endExec(result, err)
+	return result, err
+}
+
+func queryWithHooks(ctx context.Context, queryer queryer, table, query string, args ...any) (*sql.Rows, *queryHook, error) {
+	ctx, hook := beginQuery(ctx, table, "select", query)
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		hook.end(err)
+		return nil, nil, err
+	}
+	return rows, hook, nil
+}
+
+`)
+
+	if m.config.GenerateQueries {
+		io.WriteString(w, `func queryRowWithHooks(ctx context.Context, queryer queryer, table, query string, args ...any) (*row, *queryHook) {
+	ctx, hook := beginQuery(ctx, table, "select", query)
+	return queryRow(ctx, queryer, query, args...), hook
+}
+
+`)
+	} else {
+		io.WriteString(w, `func queryRowWithHooks(ctx context.Context, queryer queryer, table, query string, args ...any) (*sql.Row, *queryHook) {
+	ctx, hook := beginQuery(ctx, table, "select", query)
+	return queryer.QueryRowContext(ctx, query, args...), hook
+}
+
+`)
+	}
+}
+
+func (m *Maker) generateGoCommenter(w io.Writer) {
+	if !m.config.GenerateSQLCommenter || len(m.tables) == 0 {
+		return
+	}
+	m.imports.add("net/url", "url")
+	m.imports.add("slices", "slices")
+	m.imports.add("strings", "strings")
+	io.WriteString(w, `var commenter func(ctx context.Context) map[string]string
+
+// SetCommenter sets the function that returns the key-value pairs of the SQL comments.
+// The comments are prefixed to the statements in sqlcommenter format,
+// e.g. /*app='x',op='select',route='y',table='user'*/ SELECT ...
+// The "table" and "op" keys are always set to the table name and the operation.
+// It is not safe to call SetCommenter concurrently with the queries,
+// so call it in the initialization of the program.
+func SetCommenter(f func(ctx context.Context) map[string]string) {
+	commenter = f
+}
+
+// annotate prefixes the SQL comment built by the commenter to query.
+func annotate(ctx context.Context, table, operation, query string) string {
+	if commenter == nil {
+		return query
+	}
+	tags := map[string]string{}
+	for k, v := range commenter(ctx) {
+		tags[k] = v
+	}
+	tags["table"] = table
+	tags["op"] = operation
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var buf strings.Builder
+	buf.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(commentEscape(k))
+		buf.WriteString("='")
+		buf.WriteString(commentEscape(tags[k]))
+		buf.WriteString("'")
+	}
+	buf.WriteString("*/ ")
+	buf.WriteString(query)
+	return buf.String()
+}
+
+// commentEscape URL-encodes s, so s can't contain the quotes and the end of the comment.
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+`)
+}
+
+// generateGoQueries generates the Queries type.
+// Its methods are generated next to the functions by generateGoQueriesMethod.
+func (m *Maker) generateGoQueries(w io.Writer) {
+	if !m.config.GenerateQueries {
+		return
+	}
+
+	m.imports.add("errors", "errors")
+	m.imports.add("sync", "sync")
+	io.WriteString(w, `// Queries has the same methods as the generated functions,
+// and caches the prepared statements of the queries.
+// It is safe for concurrent use by multiple goroutines.
+type Queries struct {
+	db *sql.DB
+	tx *sql.Tx
+
+	// stmts is the statements prepared on db.
+	// It is shared with the Queries returned by WithTx.
+	stmts *stmtCache
+
+	// txStmts is the statements bound to tx.
+	txStmts *stmtCache
+}
+
+// NewQueries returns a new Queries that prepares the statements on db lazily.
+func NewQueries(db *sql.DB) *Queries {
+	return &Queries{
+		db:    db,
+		stmts: &stmtCache{stmts: map[string]*sql.Stmt{}},
+	}
+}
+
+// WithTx returns a copy of q that executes the queries in tx.
+// The cached statements are rebound to tx by tx.StmtContext.
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db:      q.db,
+		tx:      tx,
+		stmts:   q.stmts,
+		txStmts: &stmtCache{stmts: map[string]*sql.Stmt{}},
+	}
+}
+
+// Close closes all cached statements.
+// The Queries returned by WithTx share the statements, so they can't be used after Close too.
+func (q *Queries) Close() error {
+	return q.stmts.close()
+}
+
+// conn returns the database or the transaction without the cache.
+func (q *Queries) conn() interface {
+	execer
+	queryer
+} {
+	if q.tx != nil {
+		return q.tx
+	}
+	return q.db
+}
+
+// stmt returns the cached statement of query.
+func (q *Queries) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	prepare := func() (*sql.Stmt, error) {
+		return q.db.PrepareContext(ctx, query)
+	}
+	if q.tx == nil {
+		return q.stmts.get(query, prepare)
+	}
+	return q.txStmts.get(query, func() (*sql.Stmt, error) {
+		stmt, err := q.stmts.get(query, prepare)
+		if err != nil {
+			return nil, err
+		}
+		return q.tx.StmtContext(ctx, stmt), nil
+	})
+}
+
+// ExecContext executes query with the cached statement.
+func (q *Queries) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	stmt, err := q.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext executes query with the cached statement.
+func (q *Queries) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	stmt, err := q.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// QueryRowContext executes query without the cache,
+// because *sql.Row can't report the error of preparing the statement.
+// The generated functions use QueryContext through queryRow instead.
+func (q *Queries) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.conn().QueryRowContext(ctx, query, args...)
+}
+
+// PrepareContext returns the cached statement.
+// The statement must be closed by closeStmt instead of stmt.Close.
+func (q *Queries) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return q.stmt(ctx, query)
+}
+
+// row is the result of queryRow.
+// It is same as *sql.Row, but it is read by QueryContext,
+// so the error of preparing the cached statement is returned by Scan.
+type row struct {
+	rows *sql.Rows
+	err  error
+}
+
+func queryRow(ctx context.Context, queryer queryer, query string, args ...any) *row {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	return &row{rows: rows, err: err}
+}
+
+// Scan copies the columns of the first row into dest.
+// It returns sql.ErrNoRows if no rows match.
+func (r *row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	return r.rows.Close()
+}
+
+// closeStmt closes stmt prepared by execer unless it is cached by Queries.
+// The statements bound to a transaction are closed when the transaction ends.
+func closeStmt(execer execer, stmt *sql.Stmt) error {
+	if _, ok := execer.(*Queries); ok {
+		return nil
+	}
+	return stmt.Close()
+}
+
+// stmtCache is a cache of the prepared statements keyed by the queries.
+type stmtCache struct {
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// get returns the cached statement of query, or prepares it if it is not cached.
+func (c *stmtCache) get(query string, prepare func() (*sql.Stmt, error)) (*sql.Stmt, error) {
+	c.mu.Lock()
+	stmt, ok := c.stmts[query]
+	c.mu.Unlock()
+	if ok {
+		return stmt, nil
+	}
+
+	// prepare the statement without the lock,
+	// because it may take a long time.
+	stmt, err := prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.stmts[query]; ok {
+		// another goroutine prepared the same statement.
+		stmt.Close()
+		return cached, nil
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(c.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+`)
+
+	if m.config.GenerateSQLCommenter {
+		io.WriteString(w, `// cached returns q, or the database or the transaction without the cache if the commenter is set.
+// The comments are part of the statements, so caching them leaks the statements of every distinct comment.
+func (q *Queries) cached() interface {
+	execer
+	queryer
+} {
+	if commenter != nil {
+		return q.conn()
+	}
+	return q
+}
+
+`)
 	}
-	return *v
 }
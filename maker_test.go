@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"testing"
 	"time"
@@ -395,6 +396,126 @@ func (*Foo28) FullTextIndexes() []*FullTextIndex {
 	}
 }
 
+type Foo29 struct {
+	ID      int32
+	Foo1ID  int32
+	Name    string
+	Email   string
+	Content string
+	Point   string `ddl:",type=GEOMETRY,srid=4326"`
+}
+
+func (*Foo29) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo29) Indexes() []*Index {
+	return []*Index{
+		NewIndex("", "foo1_id", "name"),
+	}
+}
+
+func (*Foo29) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("", "email"),
+	}
+}
+
+func (*Foo29) FullTextIndexes() []*FullTextIndex {
+	return []*FullTextIndex{
+		NewFullTextIndex("", "content"),
+	}
+}
+
+func (*Foo29) SpatialIndexes() []*SpatialIndex {
+	return []*SpatialIndex{
+		NewSpatialIndex("", "point"),
+	}
+}
+
+func (*Foo29) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("", []string{"foo1_id"}, "foo1", []string{"id"}),
+	}
+}
+
+type Foo30 struct {
+	ID       int32
+	UserName string `ddl:"UserName"`
+	Order    int32
+}
+
+func (*Foo30) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo30) Indexes() []*Index {
+	return []*Index{
+		NewIndex("name_idx", "UserName"),
+	}
+}
+
+type Foo31 struct {
+	ID          int32
+	Foo1ID      int32
+	OtherFoo1ID int32
+}
+
+func (*Foo31) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo31) Indexes() []*Index {
+	return []*Index{
+		NewIndex("", "foo1_id"),
+		NewIndex("", "other_foo1_id"),
+	}
+}
+
+func (*Foo31) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("", []string{"foo1_id"}, "foo1", []string{"id"}),
+		NewForeignKey("", []string{"other_foo1_id"}, "foo1", []string{"id"}),
+	}
+}
+
+type Group struct {
+	ID    int32
+	Order int32
+	Key   string `ddl:"key"`
+}
+
+func (*Group) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Group) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_order", "order"),
+	}
+}
+
+type GroupMember struct {
+	ID         int32
+	GroupOrder int32
+}
+
+func (*GroupMember) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*GroupMember) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_group_order", "group_order"),
+	}
+}
+
+func (*GroupMember) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_group_order", []string{"group_order"}, "group", []string{"order"}),
+	}
+}
+
 type Fkp1 struct {
 	ID string
 }
@@ -667,18 +788,241 @@ func (*Fkc8) ForeignKeys() []*ForeignKey {
 	}
 }
 
+type Fkc9 struct {
+	ID       string
+	ParentID string
+}
+
+func (*Fkc9) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Fkc9) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey(
+			"fk_fkc9_parent_id",
+			[]string{"parent_id"},
+			"fkp1",
+			[]string{"id"},
+		),
+		// the primary key is used for this constraint.
+		NewForeignKey(
+			"fk_fkc9_id",
+			[]string{"id"},
+			"fkp1",
+			[]string{"id"},
+		),
+	}
+}
+
+type Fkp10 struct {
+	ID    string `ddl:",size=64"`
+	Code  [4]byte
+	Email sql.NullString `ddl:",null"`
+	Name  string
+}
+
+func (*Fkp10) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "code")
+}
+
+func (*Fkp10) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_name", "name"),
+	}
+}
+
+func (*Fkp10) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_email", "email"),
+	}
+}
+
+type Fkc10 struct {
+	ID        string
+	ParentID  string `ddl:",charset=utf8mb4,collate=utf8mb4_bin"`
+	Code      [8]byte
+	Email     string
+	Name      string
+	OtherName string `ddl:",charset=latin1"`
+}
+
+func (*Fkc10) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Fkc10) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_parent", "parent_id", "code"),
+		NewIndex("idx_email", "email"),
+		NewIndex("idx_name", "name"),
+		NewIndex("idx_other_name", "other_name"),
+	}
+}
+
+func (*Fkc10) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		// size mismatch
+		NewForeignKey("fk_fkc10_parent", []string{"parent_id", "code"}, "fkp10", []string{"id", "code"}).OnDelete(ForeignKeyOptionSetNull),
+		// nullable referenced column
+		NewForeignKey("fk_fkc10_email", []string{"email"}, "fkp10", []string{"email"}),
+		// non-unique referenced column
+		NewForeignKey("fk_fkc10_name", []string{"name"}, "fkp10", []string{"name"}),
+		// character set mismatch
+		NewForeignKey("fk_fkc10_other_name", []string{"other_name"}, "fkp10", []string{"id"}).OnUpdate(ForeignKeyOptionCascade),
+	}
+}
+
+type Version1 struct {
+	ID      int32
+	Version int64 `ddl:",version"`
+}
+
+func (*Version1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type foo32 struct {
+	ID int32
+}
+
+func (*foo32) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo33 struct {
+	ID   int32
+	name string
+}
+
+func (*Foo33) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type foo34Status string
+
+type Foo34 struct {
+	ID     int32
+	Status *foo34Status
+}
+
+func (*Foo34) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Version2 struct {
+	ID      int32  `ddl:",version"`
+	Version string `ddl:",version"`
+}
+
+func (*Version2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Version4 struct {
+	ID      int32
+	Version *int32 `ddl:",version"`
+}
+
+func (*Version4) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Version5 struct {
+	ID      int32
+	Version string `ddl:",version"`
+}
+
+func (*Version5) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Version3 struct {
+	ID      int32
+	Version int32 `ddl:",version,null,auto"`
+	Flag    bool  `ddl:",version"`
+}
+
+func (*Version3) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type SoftDelete1 struct {
+	ID        int32
+	DeletedAt sql.NullTime `ddl:",null,softdelete"`
+}
+
+func (*SoftDelete1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type SoftDelete2 struct {
+	ID        int32
+	DeletedAt time.Time  `ddl:",softdelete"`
+	RemovedAt *time.Time `ddl:",null,softdelete"`
+}
+
+func (*SoftDelete2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type SoftDelete3 struct {
+	ID        *time.Time `ddl:",null,softdelete"`
+	DeletedAt *int64     `ddl:",null,softdelete"`
+}
+
+func (*SoftDelete3) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type SoftDelete4 struct {
+	ID        int32
+	DeletedAt *int64 `ddl:",null,softdelete"`
+}
+
+func (*SoftDelete4) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Timestamp1 struct {
+	ID        int32
+	CreatedAt time.Time `ddl:",created"`
+	UpdatedAt time.Time `ddl:",updated,size=3"`
+}
+
+func (*Timestamp1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Timestamp2 struct {
+	ID        time.Time    `ddl:",created"`
+	CreatedAt time.Time    `ddl:",created,updated"`
+	UpdatedAt *time.Time   `ddl:",null,updated"`
+	DeletedAt sql.NullTime `ddl:",null,updated"`
+	Date      time.Time    `ddl:",created,type=DATE"`
+}
+
+func (*Timestamp2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
 func testMaker(t *testing.T, structs []any, ddl string) {
 	t.Helper()
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	m, err := New(&Config{
+	testMakerWithConfig(t, &Config{
 		DB: &DBConfig{
 			Engine:  "InnoDB",
 			Charset: "utf8mb4",
 			Collate: "utf8mb4_bin",
 		},
-	})
+	}, structs, ddl)
+}
+
+func testMakerWithConfig(t *testing.T, config *Config, structs []any, ddl string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	m, err := New(config)
 	if err != nil {
 		t.Fatalf("failed to initialize Maker: %v", err)
 	}
@@ -753,14 +1097,19 @@ func setupDatabase(ctx context.Context, t testing.TB) (db *sql.DB, ok bool) {
 
 func testMakerError(t *testing.T, structs []any, wantErr []string) {
 	t.Helper()
-
-	m, err := New(&Config{
+	testMakerErrorWithConfig(t, &Config{
 		DB: &DBConfig{
 			Engine:  "InnoDB",
 			Charset: "utf8mb4",
 			Collate: "utf8mb4_bin",
 		},
-	})
+	}, structs, wantErr)
+}
+
+func testMakerErrorWithConfig(t *testing.T, config *Config, structs []any, wantErr []string) {
+	t.Helper()
+
+	m, err := New(config)
 	if err != nil {
 		t.Fatalf("failed to initialize Maker: %v", err)
 	}
@@ -797,7 +1146,7 @@ func TestMaker_Generate(t *testing.T) {
 		"DROP TABLE IF EXISTS `foo2_customized`;\n\n"+
 		"CREATE TABLE `foo2_customized` (\n"+
 		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
-		"    `name` VARCHAR(191) NOT NULL INVISIBLE COMMENT '\\'コメント\\'',\n"+
+		"    `name` VARCHAR(191) NOT NULL INVISIBLE COMMENT 'コメント',\n"+
 		"    INDEX `idx_name` (`name`),\n"+
 		"    PRIMARY KEY (`id`)\n"+
 		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
@@ -1147,8 +1496,341 @@ func TestMaker_Generate(t *testing.T) {
 
 	testMakerError(t, []any{&Foo18{}, &Foo19{}}, []string{
 		`table "foo18", foreign key "fk_foo19": index required on table "foo18"`,
-		`table "foo18", foreign key "fk_foo19": column "foo19_id" and referenced column "foo19"."id" type mismatch`,
+		`table "foo18", foreign key "fk_foo19": column "foo19_id" and referenced column "foo19"."id" type mismatch: INTEGER NOT NULL vs BIGINT NOT NULL`,
+	})
+}
+
+func TestMaker_LenientTagParsing(t *testing.T) {
+	// the quotes in the comment are kept as they are.
+	testMakerWithConfig(t, &Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		LenientTagParsing: true,
+	}, []any{&Foo2{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo2_customized`;\n\n"+
+		"CREATE TABLE `foo2_customized` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `name` VARCHAR(191) NOT NULL INVISIBLE COMMENT '\\'コメント\\'',\n"+
+		"    INDEX `idx_name` (`name`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+}
+
+func TestMaker_ForeignKeyValidation(t *testing.T) {
+	testMakerError(t, []any{&Fkp10{}, &Fkc10{}}, []string{
+		`table "fkc10", foreign key "fk_fkc10_parent": column "parent_id" and referenced column "fkp10"."id" size mismatch: VARCHAR(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL vs VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_parent": ON DELETE SET NULL requires nullable column "parent_id": VARCHAR(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_parent": column "code" and referenced column "fkp10"."code" size mismatch: BINARY(8) NOT NULL vs BINARY(4) NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_parent": ON DELETE SET NULL requires nullable column "code": BINARY(8) NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_email": referenced column "fkp10"."email" must be NOT NULL: VARCHAR(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NULL`,
+		`table "fkc10", foreign key "fk_fkc10_name": referenced columns ["name"] of table "fkp10" must be the primary key or a unique index`,
+		`table "fkc10", foreign key "fk_fkc10_other_name": column "other_name" and referenced column "fkp10"."id" size mismatch: VARCHAR(191) CHARACTER SET latin1 NOT NULL vs VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_other_name": column "other_name" and referenced column "fkp10"."id" character set mismatch: VARCHAR(191) CHARACTER SET latin1 NOT NULL vs VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_other_name": column "other_name" and referenced column "fkp10"."id" collate mismatch: VARCHAR(191) CHARACTER SET latin1 NOT NULL vs VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL`,
+		`table "fkc10", foreign key "fk_fkc10_other_name": referenced columns ["id"] of table "fkp10" must be the primary key or a unique index`,
+	})
+}
+
+func TestMaker_Version(t *testing.T) {
+	testMaker(t, []any{&Version1{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `version1`;\n\n"+
+		"CREATE TABLE `version1` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `version` BIGINT NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	testMakerError(t, []any{&Version2{}, &Version4{}, &Version5{}}, []string{
+		`table "version2", column "id": version column can't be a part of the primary key`,
+		`table "version2", column "version": duplicated version column`,
+		`table "version4", column "version": the field of version column can't be a pointer`,
+		`table "version5", column "version": version column must be an integer: VARCHAR(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL`,
+	})
+
+	testMakerError(t, []any{&Version3{}}, []string{
+		`table "version3", column "version": version column must be NOT NULL`,
+		`table "version3", column "version": version column can't be AUTO_INCREMENT`,
+		`table "version3", column "flag": duplicated version column`,
+	})
+}
+
+func TestMaker_SoftDelete(t *testing.T) {
+	testMaker(t, []any{&SoftDelete1{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `soft_delete1`;\n\n"+
+		"CREATE TABLE `soft_delete1` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `deleted_at` DATETIME(6) NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	testMakerError(t, []any{&SoftDelete2{}, &SoftDelete3{}, &SoftDelete4{}}, []string{
+		`table "soft_delete2", column "deleted_at": soft delete column must be NULL`,
+		`table "soft_delete2", column "removed_at": duplicated soft delete column`,
+		`table "soft_delete3", column "id": soft delete column can't be a part of the primary key`,
+		`table "soft_delete3", column "deleted_at": duplicated soft delete column`,
+		`table "soft_delete4", column "deleted_at": soft delete column must be a time: BIGINT NULL`,
+	})
+}
+
+func TestMaker_Timestamp(t *testing.T) {
+	testMaker(t, []any{&Timestamp1{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `timestamp1`;\n\n"+
+		"CREATE TABLE `timestamp1` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `created_at` DATETIME(6) NOT NULL,\n"+
+		"    `updated_at` DATETIME(3) NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	testMakerError(t, []any{&Timestamp2{}}, []string{
+		`table "timestamp2", column "id": timestamp column can't be a part of the primary key`,
+		`table "timestamp2", column "created_at": column can't be both created and updated`,
+		`table "timestamp2", column "created_at": duplicated created column`,
+		`table "timestamp2", column "updated_at": duplicated updated column`,
+		`table "timestamp2", column "updated_at": the field of timestamp column must be time.Time`,
+		`table "timestamp2", column "deleted_at": duplicated updated column`,
+		`table "timestamp2", column "deleted_at": the field of timestamp column must be time.Time`,
+		`table "timestamp2", column "date": duplicated created column`,
+		`table "timestamp2", column "date": timestamp column must be DATETIME or TIMESTAMP: DATE NOT NULL`,
+	})
+}
+
+func TestMaker_AutoCreateFKIndex(t *testing.T) {
+	testMakerWithConfig(t, &Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		AutoCreateFKIndex: true,
+	}, []any{&Fkp1{}, &Fkc9{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `fkp1`;\n\n"+
+		"CREATE TABLE `fkp1` (\n"+
+		"    `id` VARCHAR(191) NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n\n"+
+		"DROP TABLE IF EXISTS `fkc9`;\n\n"+
+		"CREATE TABLE `fkc9` (\n"+
+		"    `id` VARCHAR(191) NOT NULL,\n"+
+		"    `parent_id` VARCHAR(191) NOT NULL,\n"+
+		"    INDEX `fk_fkc9_parent_id` (`parent_id`),\n"+
+		"    CONSTRAINT `fk_fkc9_parent_id` FOREIGN KEY (`parent_id`) REFERENCES `fkp1` (`id`),\n"+
+		"    CONSTRAINT `fk_fkc9_id` FOREIGN KEY (`id`) REFERENCES `fkp1` (`id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+}
+
+func TestMaker_Naming(t *testing.T) {
+	// generate the omitted names
+	testMaker(t, []any{&Foo1{}, &Foo29{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo1`;\n\n"+
+		"CREATE TABLE `foo1` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n\n"+
+		"DROP TABLE IF EXISTS `foo29`;\n\n"+
+		"CREATE TABLE `foo29` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `foo1_id` INTEGER NOT NULL,\n"+
+		"    `name` VARCHAR(191) NOT NULL,\n"+
+		"    `email` VARCHAR(191) NOT NULL,\n"+
+		"    `content` VARCHAR(191) NOT NULL,\n"+
+		"    `point` GEOMETRY NOT NULL SRID 4326,\n"+
+		"    INDEX `idx_foo29_foo1_id_name` (`foo1_id`, `name`),\n"+
+		"    UNIQUE `uniq_foo29_email` (`email`),\n"+
+		"    FULLTEXT INDEX `ft_foo29_content` (`content`),\n"+
+		"    SPATIAL INDEX `sp_foo29_point` (`point`),\n"+
+		"    CONSTRAINT `fk_foo29_foo1` FOREIGN KEY (`foo1_id`) REFERENCES `foo1` (`id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// the foreign keys to the same table
+	testMaker(t, []any{&Foo1{}, &Foo31{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo1`;\n\n"+
+		"CREATE TABLE `foo1` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n\n"+
+		"DROP TABLE IF EXISTS `foo31`;\n\n"+
+		"CREATE TABLE `foo31` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `foo1_id` INTEGER NOT NULL,\n"+
+		"    `other_foo1_id` INTEGER NOT NULL,\n"+
+		"    INDEX `idx_foo31_foo1_id` (`foo1_id`),\n"+
+		"    INDEX `idx_foo31_other_foo1_id` (`other_foo1_id`),\n"+
+		"    CONSTRAINT `fk_foo31_foo1` FOREIGN KEY (`foo1_id`) REFERENCES `foo1` (`id`),\n"+
+		"    CONSTRAINT `fk_foo31_foo1_2` FOREIGN KEY (`other_foo1_id`) REFERENCES `foo1` (`id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// customize the templates
+	testMakerWithConfig(t, &Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		Naming: &NamingConfig{
+			Index:         "{{.Table}}_{{.Columns}}_idx",
+			UniqueIndex:   "{{.Table}}_{{.Columns}}_uniq",
+			FullTextIndex: "{{.Table}}_{{.Columns}}_ft",
+			SpatialIndex:  "{{.Table}}_{{.Columns}}_sp",
+			ForeignKey:    "{{.Table}}_{{.Columns}}_{{.RefTable}}_{{.RefColumns}}_fk",
+		},
+	}, []any{&Foo1{}, &Foo29{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo1`;\n\n"+
+		"CREATE TABLE `foo1` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n\n"+
+		"DROP TABLE IF EXISTS `foo29`;\n\n"+
+		"CREATE TABLE `foo29` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `foo1_id` INTEGER NOT NULL,\n"+
+		"    `name` VARCHAR(191) NOT NULL,\n"+
+		"    `email` VARCHAR(191) NOT NULL,\n"+
+		"    `content` VARCHAR(191) NOT NULL,\n"+
+		"    `point` GEOMETRY NOT NULL SRID 4326,\n"+
+		"    INDEX `foo29_foo1_id_name_idx` (`foo1_id`, `name`),\n"+
+		"    UNIQUE `foo29_email_uniq` (`email`),\n"+
+		"    FULLTEXT INDEX `foo29_content_ft` (`content`),\n"+
+		"    SPATIAL INDEX `foo29_point_sp` (`point`),\n"+
+		"    CONSTRAINT `foo29_foo1_id_foo1_id_fk` FOREIGN KEY (`foo1_id`) REFERENCES `foo1` (`id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// validate the names
+	testMakerErrorWithConfig(t, &Config{
+		Naming: &NamingConfig{
+			IndexPattern:  regexp.MustCompile(`^idx_`),
+			SnakeCase:     true,
+			ReservedWords: []string{"ORDER"},
+		},
+	}, []any{&Foo30{}}, []string{
+		`table "foo30", column "UserName": name is not snake_case`,
+		`table "foo30", column "order": name is a reserved word`,
+		`table "foo30", index "name_idx": name does not match the pattern "^idx_"`,
 	})
+
+	// reserved words
+	testMakerErrorWithConfig(t, &Config{
+		Naming: &NamingConfig{
+			ReservedWordPolicy: ReservedWordError,
+		},
+	}, []any{&Foo30{}}, []string{
+		`table "foo30", column "order": name is a reserved word of MySQL`,
+	})
+
+	// rename reserved words
+	testMakerWithConfig(t, &Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		Naming: &NamingConfig{
+			ReservedWordPolicy: ReservedWordRename,
+		},
+	}, []any{&Group{}, &GroupMember{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `group_`;\n\n"+
+		"CREATE TABLE `group_` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `order_` INTEGER NOT NULL,\n"+
+		"    `key` VARCHAR(191) NOT NULL,\n"+
+		"    UNIQUE `uniq_order` (`order_`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n\n"+
+		"DROP TABLE IF EXISTS `group_member`;\n\n"+
+		"CREATE TABLE `group_member` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `group_order` INTEGER NOT NULL,\n"+
+		"    INDEX `idx_group_order` (`group_order`),\n"+
+		"    CONSTRAINT `fk_group_order` FOREIGN KEY (`group_order`) REFERENCES `group_` (`order_`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// invalid template
+	_, err := New(&Config{
+		Naming: &NamingConfig{
+			Index: "{{.Table",
+		},
+	})
+	if err == nil {
+		t.Error("want some error, but not")
+	}
+}
+
+func TestMaker_StructPackagePath(t *testing.T) {
+	m, err := New(&Config{
+		PackageName:       "store",
+		StructPackagePath: "example.com/model",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddStructs(&Version1{})
+
+	var buf bytes.Buffer
+	err = m.GenerateGo(&buf)
+	if err == nil {
+		t.Fatal("want error, but not")
+	}
+	want := `myddlmaker: myddlmaker.Version1 is not declared in the package "example.com/model"`
+	if err.Error() != want {
+		t.Errorf("unexpected error: want %q, got %q", want, err.Error())
+	}
+}
+
+func TestMaker_StructPackagePath_Unexported(t *testing.T) {
+	tests := []struct {
+		s    any
+		want string
+	}{
+		{
+			s:    &foo32{},
+			want: "myddlmaker: myddlmaker.foo32 is not exported, so it can't be used from another package",
+		},
+		{
+			s:    &Foo33{},
+			want: "myddlmaker: the field name of myddlmaker.Foo33 is not exported, so it can't be used from another package",
+		},
+		{
+			s:    &Foo34{},
+			want: "myddlmaker: the type myddlmaker.foo34Status of the field myddlmaker.Foo34.Status is not exported, so it can't be used from another package",
+		},
+	}
+	for _, tt := range tests {
+		m, err := New(&Config{
+			PackageName:       "store",
+			StructPackagePath: "github.com/shogo82148/myddlmaker",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.AddStructs(tt.s)
+
+		var buf bytes.Buffer
+		err = m.GenerateGo(&buf)
+		if err == nil {
+			t.Errorf("%T: want error, but not", tt.s)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("unexpected error: want %q, got %q", tt.want, err.Error())
+		}
+	}
 }
 
 func TestMaker_GenerateGo(t *testing.T) {
@@ -1179,7 +1861,7 @@ func TestMaker_GenerateGo(t *testing.T) {
 			defer cancel()
 
 			var buf bytes.Buffer
-			args := []string{"test"}
+			args := []string{"test", "-tags", "fake"}
 			cmd := exec.CommandContext(ctx, goTool(), args...)
 			cmd.Stdout = &buf
 			cmd.Stderr = &buf
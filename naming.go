@@ -0,0 +1,358 @@
+package myddlmaker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// NamingConfig is a configuration of the naming conventions.
+type NamingConfig struct {
+	// Index is a template for the names of indexes that are omitted.
+	// If it is empty, "idx_{{.Table}}_{{.Columns}}" is used.
+	Index string
+
+	// UniqueIndex is a template for the names of unique indexes that are omitted.
+	// If it is empty, "uniq_{{.Table}}_{{.Columns}}" is used.
+	UniqueIndex string
+
+	// FullTextIndex is a template for the names of full-text indexes that are omitted.
+	// If it is empty, "ft_{{.Table}}_{{.Columns}}" is used.
+	FullTextIndex string
+
+	// SpatialIndex is a template for the names of spatial indexes that are omitted.
+	// If it is empty, "sp_{{.Table}}_{{.Columns}}" is used.
+	SpatialIndex string
+
+	// ForeignKey is a template for the names of foreign key constraints that are omitted.
+	// If it is empty, "fk_{{.Table}}_{{.RefTable}}" is used.
+	// If the generated name is already used in the table, a numeric suffix such as "_2" is appended.
+	ForeignKey string
+
+	// TablePattern is a pattern that table names must match.
+	TablePattern *regexp.Regexp
+
+	// ColumnPattern is a pattern that column names must match.
+	ColumnPattern *regexp.Regexp
+
+	// IndexPattern is a pattern that index names must match.
+	IndexPattern *regexp.Regexp
+
+	// ConstraintPattern is a pattern that foreign key constraint names must match.
+	ConstraintPattern *regexp.Regexp
+
+	// SnakeCase requires all names to be snake_case.
+	SnakeCase bool
+
+	// ReservedWords is a list of words that can't be used as names.
+	// The words are case-insensitive.
+	ReservedWords []string
+
+	// ReservedWordPolicy is the policy for the names that are reserved words of MySQL.
+	// See [IsReservedWord] for the list of the reserved words.
+	ReservedWordPolicy ReservedWordPolicy
+
+	// ReservedWord is a template for renaming the names that are reserved words of MySQL.
+	// It is used only if ReservedWordPolicy is ReservedWordRename.
+	// If it is empty, "{{.Name}}_" is used.
+	ReservedWord string
+}
+
+// ReservedWordPolicy is a policy for the names that are reserved words of MySQL.
+type ReservedWordPolicy int
+
+const (
+	// ReservedWordAllow allows the reserved words.
+	// They work in the generated DDL and Go code because all identifiers are quoted,
+	// but hand-written SQL must quote them too.
+	ReservedWordAllow ReservedWordPolicy = iota
+
+	// ReservedWordWarn logs warnings for the reserved words.
+	ReservedWordWarn
+
+	// ReservedWordError reports errors for the reserved words.
+	ReservedWordError
+
+	// ReservedWordRename renames the table names and the column names
+	// that are derived from the Go names, using the ReservedWord template.
+	// The references to the renamed columns in the keys and the indexes are also renamed.
+	// The names specified explicitly are not renamed, but warned.
+	ReservedWordRename
+)
+
+// NamingData is the data passed to the templates of NamingConfig.
+type NamingData struct {
+	// Name is the original name.
+	// It is available only in the ReservedWord template.
+	Name string
+
+	// Table is the name of the table.
+	Table string
+
+	// Columns is the names of the columns joined with "_".
+	Columns string
+
+	// RefTable is the name of the referenced table.
+	// It is available only in the templates for foreign key constraints.
+	RefTable string
+
+	// RefColumns is the names of the referenced columns joined with "_".
+	// It is available only in the templates for foreign key constraints.
+	RefColumns string
+}
+
+type naming struct {
+	reservedWordPolicy ReservedWordPolicy
+	reservedWord       *template.Template
+
+	index         *template.Template
+	uniqueIndex   *template.Template
+	fullTextIndex *template.Template
+	spatialIndex  *template.Template
+	foreignKey    *template.Template
+}
+
+func newNaming(config *NamingConfig) (*naming, error) {
+	if config == nil {
+		config = new(NamingConfig)
+	}
+
+	var n naming
+	var err error
+	n.reservedWordPolicy = config.ReservedWordPolicy
+	if n.reservedWord, err = parseNamingTemplate("reserved word", config.ReservedWord, "{{.Name}}_"); err != nil {
+		return nil, err
+	}
+	if n.index, err = parseNamingTemplate("index", config.Index, "idx_{{.Table}}_{{.Columns}}"); err != nil {
+		return nil, err
+	}
+	if n.uniqueIndex, err = parseNamingTemplate("unique index", config.UniqueIndex, "uniq_{{.Table}}_{{.Columns}}"); err != nil {
+		return nil, err
+	}
+	if n.fullTextIndex, err = parseNamingTemplate("full-text index", config.FullTextIndex, "ft_{{.Table}}_{{.Columns}}"); err != nil {
+		return nil, err
+	}
+	if n.spatialIndex, err = parseNamingTemplate("spatial index", config.SpatialIndex, "sp_{{.Table}}_{{.Columns}}"); err != nil {
+		return nil, err
+	}
+	if n.foreignKey, err = parseNamingTemplate("foreign key", config.ForeignKey, "fk_{{.Table}}_{{.RefTable}}"); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func parseNamingTemplate(name, text, def string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(withDefault(text, def))
+	if err != nil {
+		return nil, fmt.Errorf("myddlmaker: failed to parse the naming template for %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func execNamingTemplate(tmpl *template.Template, data *NamingData) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("myddlmaker: failed to execute the naming template for %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// renameReservedWords renames the table names and the column names that are reserved words.
+func (n *naming) renameReservedWords(tables []*table) error {
+	if n.reservedWordPolicy != ReservedWordRename {
+		return nil
+	}
+
+	// rename the tables and the columns.
+	// key: the original table name
+	tableNames := map[string]string{}
+	// key: the original table name, the original column name
+	columnNames := map[[2]string]string{}
+	for _, t := range tables {
+		for i, col := range t.columns {
+			if col.explicitName || !IsReservedWord(col.name) {
+				continue
+			}
+			name, err := execNamingTemplate(n.reservedWord, &NamingData{
+				Name:  col.name,
+				Table: t.name,
+			})
+			if err != nil {
+				return err
+			}
+			columnNames[[2]string{t.name, col.name}] = name
+			tmp := *col // shallow copy
+			tmp.name = name
+			t.columns[i] = &tmp
+		}
+
+		if t.explicitName || !IsReservedWord(t.name) {
+			continue
+		}
+		name, err := execNamingTemplate(n.reservedWord, &NamingData{
+			Name:  t.name,
+			Table: t.name,
+		})
+		if err != nil {
+			return err
+		}
+		tableNames[t.name] = name
+	}
+	if len(tableNames) == 0 && len(columnNames) == 0 {
+		return nil
+	}
+
+	// rename the references.
+	renameColumns := func(table string, columns []string) []string {
+		ret := make([]string, len(columns))
+		for i, col := range columns {
+			ret[i] = withDefault(columnNames[[2]string{table, col}], col)
+		}
+		return ret
+	}
+	for _, t := range tables {
+		if t.primaryKey != nil {
+			t.primaryKey = &PrimaryKey{
+				columns: renameColumns(t.name, t.primaryKey.columns),
+			}
+		}
+		for i, idx := range t.indexes {
+			tmp := *idx // shallow copy
+			tmp.columns = renameColumns(t.name, idx.columns)
+			tmp.order = make(map[string]string, len(idx.order))
+			for col, order := range idx.order {
+				tmp.order[withDefault(columnNames[[2]string{t.name, col}], col)] = order
+			}
+			t.indexes[i] = &tmp
+		}
+		for i, idx := range t.uniqueIndexes {
+			tmp := *idx // shallow copy
+			tmp.columns = renameColumns(t.name, idx.columns)
+			t.uniqueIndexes[i] = &tmp
+		}
+		for i, idx := range t.fullTextIndexes {
+			tmp := *idx // shallow copy
+			tmp.columns = renameColumns(t.name, idx.columns)
+			t.fullTextIndexes[i] = &tmp
+		}
+		for i, idx := range t.spatialIndexes {
+			tmp := *idx // shallow copy
+			tmp.column = withDefault(columnNames[[2]string{t.name, idx.column}], idx.column)
+			t.spatialIndexes[i] = &tmp
+		}
+		for i, fk := range t.foreignKeys {
+			tmp := *fk // shallow copy
+			tmp.columns = renameColumns(t.name, fk.columns)
+			tmp.references = renameColumns(fk.table, fk.references)
+			tmp.table = withDefault(tableNames[fk.table], fk.table)
+			t.foreignKeys[i] = &tmp
+		}
+	}
+	for _, t := range tables {
+		t.name = withDefault(tableNames[t.name], t.name)
+	}
+	return nil
+}
+
+// nameTable fills the names of indexes and constraints that are omitted.
+func (n *naming) nameTable(t *table) error {
+	var err error
+	for i, idx := range t.indexes {
+		if idx.name != "" {
+			continue
+		}
+		tmp := *idx // shallow copy
+		tmp.name, err = execNamingTemplate(n.index, &NamingData{
+			Table:   t.name,
+			Columns: strings.Join(idx.columns, "_"),
+		})
+		if err != nil {
+			return err
+		}
+		t.indexes[i] = &tmp
+	}
+
+	for i, idx := range t.uniqueIndexes {
+		if idx.name != "" {
+			continue
+		}
+		tmp := *idx // shallow copy
+		tmp.name, err = execNamingTemplate(n.uniqueIndex, &NamingData{
+			Table:   t.name,
+			Columns: strings.Join(idx.columns, "_"),
+		})
+		if err != nil {
+			return err
+		}
+		t.uniqueIndexes[i] = &tmp
+	}
+
+	for i, idx := range t.fullTextIndexes {
+		if idx.name != "" {
+			continue
+		}
+		tmp := *idx // shallow copy
+		tmp.name, err = execNamingTemplate(n.fullTextIndex, &NamingData{
+			Table:   t.name,
+			Columns: strings.Join(idx.columns, "_"),
+		})
+		if err != nil {
+			return err
+		}
+		t.fullTextIndexes[i] = &tmp
+	}
+
+	for i, idx := range t.spatialIndexes {
+		if idx.name != "" {
+			continue
+		}
+		tmp := *idx // shallow copy
+		tmp.name, err = execNamingTemplate(n.spatialIndex, &NamingData{
+			Table:   t.name,
+			Columns: idx.column,
+		})
+		if err != nil {
+			return err
+		}
+		t.spatialIndexes[i] = &tmp
+	}
+
+	// the default name of the foreign keys doesn't have the columns,
+	// so the foreign keys to the same table get the numeric suffixes, e.g. "fk_post_user_2".
+	used := map[string]bool{}
+	for _, fk := range t.foreignKeys {
+		if fk.name != "" {
+			used[fk.name] = true
+		}
+	}
+	for i, fk := range t.foreignKeys {
+		if fk.name != "" {
+			continue
+		}
+		tmp := *fk // shallow copy
+		tmp.name, err = execNamingTemplate(n.foreignKey, &NamingData{
+			Table:      t.name,
+			Columns:    strings.Join(fk.columns, "_"),
+			RefTable:   fk.table,
+			RefColumns: strings.Join(fk.references, "_"),
+		})
+		if err != nil {
+			return err
+		}
+		name := tmp.name
+		for j := 2; used[tmp.name]; j++ {
+			tmp.name = name + "_" + strconv.Itoa(j)
+		}
+		used[tmp.name] = true
+		t.foreignKeys[i] = &tmp
+	}
+	return nil
+}
+
+// maxIdentifierLength is the maximum length of identifiers.
+// https://dev.mysql.com/doc/refman/8.0/en/identifier-length.html
+const maxIdentifierLength = 64
+
+var snakeCasePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
@@ -92,3 +92,69 @@ var commonInitialisms = map[string]bool{
 	"XSS":   true,
 	"OAuth": true,
 }
+
+// snakeToCamel converts a snake_case name to a CamelCase name that is valid as a Go identifier.
+// The characters other than letters and digits are treated as separators.
+func snakeToCamel(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+		ch, n := utf8.DecodeRuneInString(word)
+		buf.WriteRune(unicode.ToUpper(ch))
+		buf.WriteString(word[n:])
+	}
+	return buf.String()
+}
+
+// lowerCamel converts the exported Go name to the unexported one.
+// The leading initialism is also converted, e.g. "UserID" to "userID" and "URLPath" to "urlPath".
+func lowerCamel(s string) string {
+	runes := []rune(s)
+	var i int
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	switch {
+	case i == 0:
+		return s
+	case i == 1 || i == len(runes):
+		// "User" to "user", "ID" to "id"
+	default:
+		// "URLPath" to "urlPath"
+		i--
+	}
+	for j := 0; j < i; j++ {
+		runes[j] = unicode.ToLower(runes[j])
+	}
+	return string(runes)
+}
+
+// pluralize returns the plural form of the CamelCase name s for the names of the generated functions.
+// It only handles the regular plurals, e.g. "Post" to "Posts", "Category" to "Categories",
+// "Address" to "Addresses" and "UserID" to "UserIDs".
+func pluralize(s string) string {
+	last, n := utf8.DecodeLastRuneInString(s)
+	switch {
+	case s == "":
+		return s
+	case unicode.IsUpper(last):
+		// the initialisms, e.g. "ID" to "IDs"
+		return s + "s"
+	case last == 'y':
+		prev, _ := utf8.DecodeLastRuneInString(s[:len(s)-n])
+		if !strings.ContainsRune("aeiouAEIOU", prev) {
+			return s[:len(s)-n] + "ies"
+		}
+	case last == 's', last == 'x', last == 'z', strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
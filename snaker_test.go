@@ -66,8 +66,137 @@ func TestCamelToSnake(t *testing.T) {
 	}
 }
 
+func TestSnakeToCamel(t *testing.T) {
+	testcases := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "",
+			want: "",
+		},
+		{
+			in:   "one",
+			want: "One",
+		},
+		{
+			in:   "id",
+			want: "ID",
+		},
+		{
+			in:   "uniq_user_id",
+			want: "UniqUserID",
+		},
+		{
+			in:   "idx-name__2",
+			want: "IdxName2",
+		},
+		{
+			in:   "https_url",
+			want: "HTTPSURL",
+		},
+	}
+
+	for _, tc := range testcases {
+		got := snakeToCamel(tc.in)
+		if got != tc.want {
+			t.Errorf("want %q, got %q", tc.want, got)
+		}
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	testcases := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "",
+			want: "",
+		},
+		{
+			in:   "name",
+			want: "name",
+		},
+		{
+			in:   "Name",
+			want: "name",
+		},
+		{
+			in:   "ID",
+			want: "id",
+		},
+		{
+			in:   "UserID",
+			want: "userID",
+		},
+		{
+			in:   "URLPath",
+			want: "urlPath",
+		},
+	}
+
+	for _, tc := range testcases {
+		got := lowerCamel(tc.in)
+		if got != tc.want {
+			t.Errorf("want %q, got %q", tc.want, got)
+		}
+	}
+}
+
 func BenchmarkCamelToSnake(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		camelToSnake("BenchmarkCamelToSnake")
 	}
 }
+
+func TestPluralize(t *testing.T) {
+	testcases := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "",
+			want: "",
+		},
+		{
+			in:   "Post",
+			want: "Posts",
+		},
+		{
+			in:   "Category",
+			want: "Categories",
+		},
+		{
+			in:   "Key",
+			want: "Keys",
+		},
+		{
+			in:   "Address",
+			want: "Addresses",
+		},
+		{
+			in:   "Status",
+			want: "Statuses",
+		},
+		{
+			in:   "Box",
+			want: "Boxes",
+		},
+		{
+			in:   "Branch",
+			want: "Branches",
+		},
+		{
+			in:   "UserID",
+			want: "UserIDs",
+		},
+	}
+
+	for _, tc := range testcases {
+		got := pluralize(tc.in)
+		if got != tc.want {
+			t.Errorf("%q: want %q, got %q", tc.in, tc.want, got)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -42,8 +43,12 @@ type TableComment interface {
 }
 
 type table struct {
-	name            string
-	rawName         string
+	name         string
+	rawName      string
+	explicitName bool   // the name is specified by the Table method
+	pkgPath      string // the path of the package that declares the struct
+	typ          reflect.Type
+
 	columns         []*column
 	comment         *string
 	primaryKey      *PrimaryKey
@@ -54,7 +59,9 @@ type table struct {
 	spatialIndexes  []*SpatialIndex
 }
 
-func newTable(s any) (*table, error) {
+// newTable parses the struct s.
+// If lenientTag is true, the struct tags are parsed in the same way as older versions.
+func newTable(s any, lenientTag bool) (*table, error) {
 	val := reflect.ValueOf(s)
 	typ := indirect(val.Type())
 	iface := val.Interface()
@@ -64,8 +71,11 @@ func newTable(s any) (*table, error) {
 
 	var tbl table
 	tbl.rawName = typ.Name()
+	tbl.pkgPath = typ.PkgPath()
+	tbl.typ = typ
 	if t, ok := iface.(Table); ok {
 		tbl.name = t.Table()
+		tbl.explicitName = true
 	} else {
 		tbl.name = camelToSnake(typ.Name())
 	}
@@ -80,7 +90,7 @@ func newTable(s any) (*table, error) {
 	fields := reflect.VisibleFields(typ)
 	tbl.columns = make([]*column, 0, len(fields))
 	for _, f := range fields {
-		col, err := newColumn(f)
+		col, err := newColumn(typ.Name(), f, lenientTag)
 		if err != nil {
 			if !errors.Is(err, errSkipColumn) {
 				return nil, err
@@ -119,12 +129,19 @@ type column struct {
 	// rawName is the name in Go codes.
 	rawName string
 
+	// explicitName marks the name is specified by the struct tag.
+	explicitName bool
+
 	// typ is the type name in SQL queries
 	typ string
 
 	// rawType is the type name in Go codes.
 	rawType reflect.Type
 
+	// fieldType is the type of the struct field.
+	// It is different from rawType if the field is a pointer.
+	fieldType reflect.Type
+
 	size int
 
 	// autoIncr marks the column an auto increment column.
@@ -139,6 +156,18 @@ type column struct {
 	// null enables to accept NULL values.
 	null bool
 
+	// version marks the column a version column for optimistic locking.
+	version bool
+
+	// softDelete marks the column a timestamp column for soft deletes.
+	softDelete bool
+
+	// created marks the column a timestamp column that is set when the row is inserted.
+	created bool
+
+	// updated marks the column a timestamp column that is set when the row is inserted or updated.
+	updated bool
+
 	// def is the default value of the column.
 	def string
 
@@ -168,12 +197,13 @@ var nullInt64Type = reflect.TypeOf(sql.NullInt64{})
 var jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
 var myddlmakerJSON = reflect.TypeOf((*jsonMarker)(nil)).Elem()
 
-func newColumn(f reflect.StructField) (*column, error) {
+func newColumn(structName string, f reflect.StructField, lenientTag bool) (*column, error) {
 	var invalidType bool
 
 	typ := indirect(f.Type)
 	col := &column{
-		rawType: typ,
+		rawType:   typ,
+		fieldType: f.Type,
 	}
 
 	switch typ.Kind() {
@@ -267,79 +297,127 @@ func newColumn(f reflect.StructField) (*column, error) {
 		name = camelToSnake(f.Name)
 	} else if name == "-" {
 		return nil, errSkipColumn
+	} else {
+		col.explicitName = true
 	}
 	col.name = name
-	for len(remain) > 0 {
-		var opt string
-		opt, remain, _ = cutComma(remain)
-		name, val, ok := strings.Cut(opt, "=")
-		switch name {
+
+	var opts []tagOption
+	if lenientTag {
+		opts = parseLenientTagOptions(remain)
+	} else {
+		var err error
+		opts, err = parseTagOptions(remain)
+		if err != nil {
+			return nil, fmt.Errorf("myddlmaker: %s.%s: failed to parse tag: %w", structName, f.Name, err)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(opts))
+	for _, opt := range opts {
+		if !lenientTag {
+			if _, ok := seen[opt.name]; ok {
+				return nil, fmt.Errorf("myddlmaker: %s.%s: duplicated option in tag: %q", structName, f.Name, opt.name)
+			}
+			seen[opt.name] = struct{}{}
+		}
+
+		switch opt.name {
 		case "null":
-			v, err := parseBool("null", val, ok)
+			v, err := parseBool(opt)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
 			}
 			col.null = v
 		case "auto":
-			v, err := parseBool("auto", val, ok)
+			v, err := parseBool(opt)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
 			}
 			col.autoIncr = v
 		case "invisible":
-			v, err := parseBool("invisible", val, ok)
+			v, err := parseBool(opt)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
 			}
 			col.invisible = v
+		case "version":
+			v, err := parseBool(opt)
+			if err != nil {
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
+			}
+			col.version = v
+		case "softdelete":
+			v, err := parseBool(opt)
+			if err != nil {
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
+			}
+			col.softDelete = v
+		case "created":
+			v, err := parseBool(opt)
+			if err != nil {
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
+			}
+			col.created = v
+		case "updated":
+			v, err := parseBool(opt)
+			if err != nil {
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
+			}
+			col.updated = v
 		case "unsigned":
-			v, err := parseBool("unsigned", val, ok)
+			v, err := parseBool(opt)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("myddlmaker: %s.%s: %w", structName, f.Name, err)
 			}
 			col.unsigned = v
 		case "size":
-			v, err := strconv.ParseInt(val, 10, 0)
+			v, err := strconv.ParseInt(opt.value, 10, 0)
 			if err != nil {
-				return nil, fmt.Errorf("myddlmaker: failed to parse size param in tag: %w", err)
+				return nil, fmt.Errorf("myddlmaker: %s.%s: failed to parse size param in tag: %w", structName, f.Name, err)
 			}
 			col.size = int(v)
 		case "srid":
-			v, err := strconv.ParseInt(val, 10, 0)
+			v, err := strconv.ParseInt(opt.value, 10, 0)
 			if err != nil {
-				return nil, fmt.Errorf("myddlmaker: failed to parse srid param in tag: %w", err)
+				return nil, fmt.Errorf("myddlmaker: %s.%s: failed to parse srid param in tag: %w", structName, f.Name, err)
 			}
 			col.srid = ptrInt(int(v))
 		case "type":
-			col.typ = val
+			col.typ = opt.raw
 			col.unsigned = false
 			col.size = 0
 			invalidType = false
 		case "default":
-			col.def = val
+			// the default value is an SQL expression, so the quotes are kept.
+			col.def = opt.raw
 		case "charset":
-			col.charset = val
+			col.charset = opt.value
 		case "collate":
-			col.collate = val
+			col.collate = opt.value
 		case "comment":
-			col.comment = val
+			col.comment = opt.value
+		default:
+			if !lenientTag {
+				return nil, fmt.Errorf("myddlmaker: %s.%s: unknown option in tag: %q", structName, f.Name, opt.name)
+			}
 		}
 	}
 
 	if invalidType {
-		return nil, fmt.Errorf("myddlmaker: unknown type: %s", typ.String())
+		return nil, fmt.Errorf("myddlmaker: %s.%s: unknown type: %s", structName, f.Name, typ.String())
 	}
 
 	return col, nil
 }
 
-func parseBool(name, val string, ok bool) (bool, error) {
-	if !ok {
+func parseBool(opt tagOption) (bool, error) {
+	if !opt.hasValue {
 		return true, nil
 	}
-	v, err := strconv.ParseBool(val)
+	v, err := strconv.ParseBool(opt.value)
 	if err != nil {
-		return false, fmt.Errorf("myddlmaker: failed to parse %s param in tag: %w", name, err)
+		return false, fmt.Errorf("failed to parse %s param in tag: %w", opt.name, err)
 	}
 	return v, nil
 }
@@ -377,22 +455,123 @@ func isSQLNull(typ reflect.Type) bool {
 	return strings.HasPrefix(name, "Null[") && strings.HasSuffix(name, "]")
 }
 
-func cutComma(s string) (before string, after string, found bool) {
-	var cnt int
-	for i, b := range s {
-		switch b {
-		case '(':
-			cnt++
-		case ')':
-			cnt--
-			if cnt < 0 {
-				cnt = 0
-			}
-		case ',':
-			if cnt == 0 {
-				return s[:i], s[i+1:], true
+// hasIndex reports whether t has an index that can be used for looking up cols.
+func (t *table) hasIndex(cols []string) bool {
+	if hasPrefix(t.primaryKey.columns, cols) {
+		return true
+	}
+
+	for _, idx := range t.indexes {
+		if hasPrefix(idx.columns, cols) {
+			return true
+		}
+	}
+
+	for _, idx := range t.uniqueIndexes {
+		if hasPrefix(idx.columns, cols) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasUniqueKey reports whether cols are the primary key or a unique index of t.
+func (t *table) hasUniqueKey(cols []string) bool {
+	if slices.Equal(t.primaryKey.columns, cols) {
+		return true
+	}
+
+	for _, idx := range t.uniqueIndexes {
+		if slices.Equal(idx.columns, cols) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasColumn reports whether t has the column named name.
+func (t *table) hasColumn(name string) bool {
+	for _, col := range t.columns {
+		if col.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addFKIndexes adds the indexes required by the foreign key constraints of t.
+// InnoDB creates them implicitly if they are missing,
+// and names them after the constraints.
+func (t *table) addFKIndexes() {
+LOOP:
+	for _, fk := range t.foreignKeys {
+		for _, col := range fk.columns {
+			if !t.hasColumn(col) {
+				// the validator reports this error.
+				continue LOOP
 			}
 		}
+		if t.hasIndex(fk.columns) {
+			continue
+		}
+		t.indexes = append(t.indexes, NewIndex(fk.name, fk.columns...))
+	}
+}
+
+func hasPrefix(s []string, prefix []string) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// versionColumn returns the version column for optimistic locking.
+// It returns nil if the table has no version column.
+func (t *table) versionColumn() *column {
+	for _, col := range t.columns {
+		if col.version {
+			return col
+		}
+	}
+	return nil
+}
+
+// softDeleteColumn returns the timestamp column for soft deletes.
+// It returns nil if the table has no soft delete column.
+func (t *table) softDeleteColumn() *column {
+	for _, col := range t.columns {
+		if col.softDelete {
+			return col
+		}
+	}
+	return nil
+}
+
+// createdColumn returns the timestamp column that is set when the row is inserted.
+// It returns nil if the table has no created column.
+func (t *table) createdColumn() *column {
+	for _, col := range t.columns {
+		if col.created {
+			return col
+		}
+	}
+	return nil
+}
+
+// updatedColumn returns the timestamp column that is set when the row is inserted or updated.
+// It returns nil if the table has no updated column.
+func (t *table) updatedColumn() *column {
+	for _, col := range t.columns {
+		if col.updated {
+			return col
+		}
 	}
-	return s, "", false
+	return nil
 }
@@ -80,6 +80,7 @@ func TestTable(t *testing.T) {
 	want := &table{
 		name:    "foo_bar",
 		rawName: "FooBar",
+		pkgPath: "github.com/shogo82148/myddlmaker",
 		columns: []*column{
 			{name: "int8", rawName: "Int8", typ: "TINYINT"},
 			{name: "int16", rawName: "Int16", typ: "SMALLINT"},
@@ -105,7 +106,7 @@ func TestTable(t *testing.T) {
 			{name: "null_uint32", rawName: "NullUint32", typ: "INTEGER", unsigned: true, null: true},
 			{name: "p_int8", rawName: "PInt8", typ: "TINYINT"},
 			{name: "p_p_int8", rawName: "PPInt8", typ: "TINYINT"},
-			{name: "fuga", rawName: "Hoge", typ: "INTEGER"},
+			{name: "fuga", rawName: "Hoge", typ: "INTEGER", explicitName: true},
 			{name: "time", rawName: "Time", typ: "DATETIME", size: 6},
 			{name: "null_time", rawName: "NullTime", typ: "DATETIME", size: 6},
 			{name: "null_string", rawName: "NullString", typ: "VARCHAR", size: 191},
@@ -119,13 +120,14 @@ func TestTable(t *testing.T) {
 			{name: "default_value", rawName: "DefaultValue", typ: "BIGINT", def: "123"},
 		},
 	}
-	got, err := newTable(&FooBar{})
+	got, err := newTable(&FooBar{}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
 	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{}, Index{}, UniqueIndex{}, ForeignKey{})
-	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
-	if diff := cmp.Diff(want, got, opt1, opt2); diff != "" {
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType", "fieldType")
+	opt3 := cmpopts.IgnoreFields(table{}, "typ")
+	if diff := cmp.Diff(want, got, opt1, opt2, opt3); diff != "" {
 		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
 	}
 }
@@ -137,12 +139,58 @@ func TestTable_UnknownType(t *testing.T) {
 		Foo customType
 	}
 
-	_, err := newTable(&FooBar{})
+	_, err := newTable(&FooBar{}, false)
 	if err == nil {
 		t.Error("want some errors, got nil")
 	}
 }
 
+func TestTable_InvalidTag(t *testing.T) {
+	type UnknownOption struct {
+		Foo int32 `ddl:",nul"`
+	}
+	type DuplicatedOption struct {
+		Foo int32 `ddl:",null,null=false"`
+	}
+	type UnterminatedQuote struct {
+		Foo int32 `ddl:",comment='foo"`
+	}
+
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{
+			in:   &UnknownOption{},
+			want: `myddlmaker: UnknownOption.Foo: unknown option in tag: "nul"`,
+		},
+		{
+			in:   &DuplicatedOption{},
+			want: `myddlmaker: DuplicatedOption.Foo: duplicated option in tag: "null"`,
+		},
+		{
+			in:   &UnterminatedQuote{},
+			want: `myddlmaker: UnterminatedQuote.Foo: failed to parse tag: unterminated quoted value`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := newTable(tt.in, false)
+		if err == nil {
+			t.Errorf("%T: want some errors, got nil", tt.in)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%T: unexpected error: want %q, got %q", tt.in, tt.want, err.Error())
+		}
+
+		// the lenient parser ignores the errors.
+		if _, err := newTable(tt.in, true); err != nil {
+			t.Errorf("%T: unexpected error in the lenient mode: %v", tt.in, err)
+		}
+	}
+}
+
 func TestCutComma(t *testing.T) {
 	tests := []struct {
 		in     string
@@ -0,0 +1,176 @@
+package myddlmaker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// tagOption is an option in the struct tag.
+type tagOption struct {
+	// name is the name of the option.
+	name string
+
+	// value is the value of the option.
+	// If the value is quoted, the quotes are removed and the escape sequences are decoded.
+	value string
+
+	// raw is the value of the option as it is written.
+	raw string
+
+	// hasValue is true if the option has the value.
+	hasValue bool
+}
+
+// parseTagOptions parses the options of the struct tag.
+//
+// The options are separated by commas.
+// Each option is a name or a name=value pair.
+// The value may be quoted with single or double quotes, e.g. comment='a, b'.
+// In the quoted value, the quote character is escaped by doubling it or by a backslash.
+// The commas in parentheses are not separators, e.g. type=DECIMAL(9,6).
+func parseTagOptions(s string) ([]tagOption, error) {
+	var opts []tagOption
+	for len(s) > 0 {
+		opt, rest, err := cutTagOption(s)
+		if err != nil {
+			return nil, err
+		}
+		s = rest
+		if opt == "" {
+			continue
+		}
+
+		name, raw, ok := strings.Cut(opt, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("option name is missing: %q", opt)
+		}
+		value, err := unquoteTagValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of option %q: %w", name, err)
+		}
+		opts = append(opts, tagOption{
+			name:     name,
+			value:    value,
+			raw:      raw,
+			hasValue: ok,
+		})
+	}
+	return opts, nil
+}
+
+// cutTagOption slices s around the first comma that separates options.
+func cutTagOption(s string) (before, after string, err error) {
+	var depth int
+	var quote rune
+	var escaped bool
+	for i, r := range s {
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch r {
+		case '\'', '"':
+			quote = r
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				return s[:i], s[i+1:], nil
+			}
+		}
+	}
+	if quote != 0 {
+		return "", "", errors.New("unterminated quoted value")
+	}
+	return s, "", nil
+}
+
+// unquoteTagValue removes the quotes from s if s is quoted.
+// Otherwise, it returns s as it is.
+func unquoteTagValue(s string) (string, error) {
+	if len(s) < 2 {
+		return s, nil
+	}
+	quote := s[0]
+	if (quote != '\'' && quote != '"') || s[len(s)-1] != quote {
+		return s, nil
+	}
+
+	var buf strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		b := body[i]
+		switch {
+		case b == '\\':
+			if i+1 >= len(body) {
+				return "", fmt.Errorf("invalid escape sequence in %s", s)
+			}
+			i++
+			buf.WriteByte(body[i])
+		case b == quote:
+			// the quote character must be doubled.
+			if i+1 >= len(body) || body[i+1] != quote {
+				return "", fmt.Errorf("unescaped quote in %s", s)
+			}
+			i++
+			buf.WriteByte(quote)
+		default:
+			buf.WriteByte(b)
+		}
+	}
+	return buf.String(), nil
+}
+
+// cutComma is used by the lenient parser.
+// It slices s around the first comma that is not in parentheses.
+func cutComma(s string) (before string, after string, found bool) {
+	var cnt int
+	for i, b := range s {
+		switch b {
+		case '(':
+			cnt++
+		case ')':
+			cnt--
+			if cnt < 0 {
+				cnt = 0
+			}
+		case ',':
+			if cnt == 0 {
+				return s[:i], s[i+1:], true
+			}
+		}
+	}
+	return s, "", false
+}
+
+// parseLenientTagOptions parses the options of the struct tag in the same way as older versions.
+// The quotes in the values are not removed.
+func parseLenientTagOptions(s string) []tagOption {
+	var opts []tagOption
+	for len(s) > 0 {
+		var opt string
+		opt, s, _ = cutComma(s)
+		name, val, ok := strings.Cut(opt, "=")
+		opts = append(opts, tagOption{
+			name:     name,
+			value:    val,
+			raw:      val,
+			hasValue: ok,
+		})
+	}
+	return opts
+}
@@ -0,0 +1,89 @@
+package myddlmaker
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTagOptions(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []tagOption
+	}{
+		{
+			in: "null,auto",
+			want: []tagOption{
+				{name: "null"},
+				{name: "auto"},
+			},
+		},
+		{
+			in: "null=false,size=10",
+			want: []tagOption{
+				{name: "null", value: "false", raw: "false", hasValue: true},
+				{name: "size", value: "10", raw: "10", hasValue: true},
+			},
+		},
+		{
+			in: "type=DECIMAL(9,6),null",
+			want: []tagOption{
+				{name: "type", value: "DECIMAL(9,6)", raw: "DECIMAL(9,6)", hasValue: true},
+				{name: "null"},
+			},
+		},
+		{
+			in: "comment='a, b',null",
+			want: []tagOption{
+				{name: "comment", value: "a, b", raw: "'a, b'", hasValue: true},
+				{name: "null"},
+			},
+		},
+		{
+			in: `comment='it''s',default='\'',type=ENUM('a,b','c')`,
+			want: []tagOption{
+				{name: "comment", value: "it's", raw: "'it''s'", hasValue: true},
+				{name: "default", value: "'", raw: `'\''`, hasValue: true},
+				{name: "type", value: "ENUM('a,b','c')", raw: "ENUM('a,b','c')", hasValue: true},
+			},
+		},
+		{
+			in: `comment="a, b"`,
+			want: []tagOption{
+				{name: "comment", value: "a, b", raw: `"a, b"`, hasValue: true},
+			},
+		},
+		{
+			in: "null,,auto,",
+			want: []tagOption{
+				{name: "null"},
+				{name: "auto"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTagOptions(tt.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(tagOption{})); diff != "" {
+			t.Errorf("%q: unexpected options (-want/+got):\n%s", tt.in, diff)
+		}
+	}
+}
+
+func TestParseTagOptions_Error(t *testing.T) {
+	tests := []string{
+		"comment='unterminated",
+		"=value",
+		"comment='a'b'",
+	}
+
+	for _, tt := range tests {
+		if _, err := parseTagOptions(tt); err == nil {
+			t.Errorf("%q: want some error, got nil", tt)
+		}
+	}
+}
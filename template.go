@@ -0,0 +1,327 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data passed to the templates executed by GenerateTemplate.
+type TemplateData struct {
+	// PackageName is the package name of the generated Go code.
+	PackageName string
+
+	// Tag is the build constraint tag of the generated Go code.
+	Tag string
+
+	// Tables is the tables in the order of AddStructs.
+	Tables []*TemplateTable
+}
+
+// TemplateTable is a table in TemplateData.
+type TemplateTable struct {
+	// Name is the name of the table in SQL.
+	Name string
+
+	// GoName is the name of the struct.
+	GoName string
+
+	// Type is the type of the struct.
+	Type reflect.Type
+
+	// Comment is the comment of the table.
+	Comment string
+
+	// Columns is the columns in the order of the fields.
+	Columns []*TemplateColumn
+
+	// PrimaryKey is the names of the columns of the primary key.
+	PrimaryKey []string
+
+	Indexes         []*TemplateIndex
+	UniqueIndexes   []*TemplateIndex
+	FullTextIndexes []*TemplateIndex
+	SpatialIndexes  []*TemplateIndex
+	ForeignKeys     []*TemplateForeignKey
+}
+
+// TemplateColumn is a column in TemplateData.
+type TemplateColumn struct {
+	// Name is the name of the column in SQL.
+	Name string
+
+	// GoName is the name of the field.
+	GoName string
+
+	// Type is the type of the field.
+	Type reflect.Type
+
+	// SQLType is the type of the column in SQL, e.g. "VARCHAR(191)".
+	SQLType string
+
+	Null          bool
+	AutoIncrement bool
+	Unsigned      bool
+	Invisible     bool
+
+	// Version marks the column for optimistic locking.
+	Version bool
+
+	// SoftDelete marks the column for soft deletes.
+	SoftDelete bool
+
+	// Created and Updated mark the timestamp columns.
+	Created bool
+	Updated bool
+
+	// Default is the default value of the column in SQL.
+	Default string
+
+	// Comment is the comment of the column.
+	Comment string
+}
+
+// TemplateIndex is an index in TemplateData.
+type TemplateIndex struct {
+	// Name is the name of the index.
+	Name string
+
+	// Columns is the names of the columns.
+	Columns []string
+}
+
+// TemplateForeignKey is a foreign key constraint in TemplateData.
+type TemplateForeignKey struct {
+	// Name is the name of the constraint.
+	Name string
+
+	// Columns is the names of the referencing columns.
+	Columns []string
+
+	// Table is the name of the referenced table.
+	Table string
+
+	// References is the names of the referenced columns.
+	References []string
+
+	OnUpdate ForeignKeyOption
+	OnDelete ForeignKeyOption
+}
+
+// TemplateFuncs returns the functions available in the templates executed by GenerateTemplate.
+// Add them to your templates before parsing.
+//
+//	tmpl := template.New("cache_gen.go").Funcs(myddlmaker.TemplateFuncs())
+//
+// The functions are:
+//
+//   - quote: quotes an identifier with back quotes, e.g. {{quote .Name}}
+//   - snake: converts CamelCase to snake_case
+//   - camel: converts snake_case to CamelCase
+//   - goType: returns the name of a type in the generated Go code, e.g. {{goType .Type}}
+//   - imports: returns the import declaration of the packages referred to by goType and the packages of the arguments,
+//     e.g. {{imports "fmt"}}
+//   - pkColumns: returns the columns of the primary key of a table
+//   - columns: returns the columns of a table by their names, e.g. {{columns $table .Columns}}
+func TemplateFuncs() template.FuncMap {
+	return templateFuncs(newGoImports(""))
+}
+
+func templateFuncs(imports *goImports) template.FuncMap {
+	return template.FuncMap{
+		"quote":     quote,
+		"snake":     camelToSnake,
+		"camel":     snakeToCamel,
+		"goType":    imports.typeName,
+		"imports":   templateImports(imports),
+		"pkColumns": templatePKColumns,
+		"columns":   templateColumns,
+	}
+}
+
+// templateImports returns the imports function of the templates.
+// The imports of goType are collected by the first pass of generateTemplate,
+// so the second pass renders all of them even if goType is called after imports.
+func templateImports(imports *goImports) func(paths ...string) string {
+	return func(paths ...string) string {
+		for _, p := range paths {
+			if _, ok := imports.names[p]; !ok {
+				imports.add(p, path.Base(p))
+			}
+		}
+		specs := imports.specs()
+		if len(specs) == 0 {
+			return ""
+		}
+		return "import (\n" + strings.Join(specs, "\n") + "\n)\n"
+	}
+}
+
+func templatePKColumns(t *TemplateTable) ([]*TemplateColumn, error) {
+	return templateColumns(t, t.PrimaryKey)
+}
+
+func templateColumns(t *TemplateTable, names []string) ([]*TemplateColumn, error) {
+	ret := make([]*TemplateColumn, 0, len(names))
+LOOP:
+	for _, name := range names {
+		for _, col := range t.Columns {
+			if col.Name == name {
+				ret = append(ret, col)
+				continue LOOP
+			}
+		}
+		return nil, fmt.Errorf("table %q: column %q not found", t.Name, name)
+	}
+	return ret, nil
+}
+
+// GenerateTemplateFile executes tmpl and writes the output to path.
+// See GenerateTemplate for details.
+// The output is formatted as Go source code if path or the name of tmpl ends with ".go".
+func (m *Maker) GenerateTemplateFile(path string, tmpl *template.Template) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to open %q: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := m.generateTemplate(f, tmpl, isGoFileName(path) || isGoFileName(tmpl.Name())); err != nil {
+		return fmt.Errorf("myddlmaker: failed to generate %q: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+// GenerateTemplate executes tmpl with *TemplateData and writes the output to w.
+// The functions of TemplateFuncs are available in tmpl,
+// and goType refers to the types in the same way as GenerateGo.
+// tmpl is executed twice: the first pass collects the packages referred to by goType,
+// and the output of the second pass has their import declaration rendered by imports.
+// If the name of tmpl ends with ".go" or ".go.tmpl", the output is formatted as Go source code.
+func (m *Maker) GenerateTemplate(w io.Writer, tmpl *template.Template) error {
+	return m.generateTemplate(w, tmpl, isGoFileName(tmpl.Name()))
+}
+
+func (m *Maker) generateTemplate(w io.Writer, tmpl *template.Template, goSource bool) error {
+	if err := m.parse(); err != nil {
+		return err
+	}
+	if err := m.initGoImports(); err != nil {
+		return err
+	}
+
+	// bind goType to the imports of the generated Go code.
+	// tmpl is cloned not to modify the template of the caller.
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to clone the template %s: %w", tmpl.Name(), err)
+	}
+	clone.Funcs(templateFuncs(m.imports))
+
+	// the first pass collects the imports of goType.
+	data := m.templateData()
+	if err := clone.Execute(io.Discard, data); err != nil {
+		return fmt.Errorf("myddlmaker: failed to execute the template %s: %w", tmpl.Name(), err)
+	}
+	var buf bytes.Buffer
+	if err := clone.Execute(&buf, data); err != nil {
+		return fmt.Errorf("myddlmaker: failed to execute the template %s: %w", tmpl.Name(), err)
+	}
+
+	source := buf.Bytes()
+	if goSource {
+		source, err = format.Source(source)
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to format the output of the template %s: %w", tmpl.Name(), err)
+		}
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+func isGoFileName(name string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(name, ".tmpl"), ".go")
+}
+
+func (m *Maker) templateData() *TemplateData {
+	tables := make([]*TemplateTable, 0, len(m.tables))
+	for _, table := range m.tables {
+		tables = append(tables, newTemplateTable(table))
+	}
+	return &TemplateData{
+		PackageName: m.config.PackageName,
+		Tag:         m.config.Tag,
+		Tables:      tables,
+	}
+}
+
+func newTemplateTable(table *table) *TemplateTable {
+	t := &TemplateTable{
+		Name:   table.name,
+		GoName: table.rawName,
+		Type:   table.typ,
+	}
+	if table.comment != nil {
+		t.Comment = *table.comment
+	}
+	for _, col := range table.columns {
+		t.Columns = append(t.Columns, newTemplateColumn(col))
+	}
+	if table.primaryKey != nil {
+		t.PrimaryKey = table.primaryKey.columns
+	}
+	for _, idx := range table.indexes {
+		t.Indexes = append(t.Indexes, &TemplateIndex{Name: idx.name, Columns: idx.columns})
+	}
+	for _, idx := range table.uniqueIndexes {
+		t.UniqueIndexes = append(t.UniqueIndexes, &TemplateIndex{Name: idx.name, Columns: idx.columns})
+	}
+	for _, idx := range table.fullTextIndexes {
+		t.FullTextIndexes = append(t.FullTextIndexes, &TemplateIndex{Name: idx.name, Columns: idx.columns})
+	}
+	for _, idx := range table.spatialIndexes {
+		t.SpatialIndexes = append(t.SpatialIndexes, &TemplateIndex{Name: idx.name, Columns: []string{idx.column}})
+	}
+	for _, fk := range table.foreignKeys {
+		t.ForeignKeys = append(t.ForeignKeys, &TemplateForeignKey{
+			Name:       fk.name,
+			Columns:    fk.columns,
+			Table:      fk.table,
+			References: fk.references,
+			OnUpdate:   fk.onUpdate,
+			OnDelete:   fk.onDelete,
+		})
+	}
+	return t
+}
+
+func newTemplateColumn(col *column) *TemplateColumn {
+	sqlType := col.typ
+	if col.size != 0 {
+		sqlType += "(" + strconv.Itoa(col.size) + ")"
+	}
+	return &TemplateColumn{
+		Name:          col.name,
+		GoName:        col.rawName,
+		Type:          col.fieldType,
+		SQLType:       sqlType,
+		Null:          col.null,
+		AutoIncrement: col.autoIncr,
+		Unsigned:      col.unsigned,
+		Invisible:     col.invisible,
+		Version:       col.version,
+		SoftDelete:    col.softDelete,
+		Created:       col.created,
+		Updated:       col.updated,
+		Default:       col.def,
+		Comment:       col.comment,
+	}
+}
@@ -0,0 +1,113 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+type Template1 struct {
+	UserID    int64
+	Name      string
+	Nickname  *string
+	CreatedAt time.Time
+}
+
+func (*Template1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("user_id", "name")
+}
+
+func TestMaker_GenerateTemplate(t *testing.T) {
+	m, err := New(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddStructs(&Template1{})
+
+	// goType is called after imports.
+	const text = `package {{.PackageName}}
+
+{{imports "fmt"}}{{range .Tables}}
+func {{.GoName}}CacheKey(v *{{goType .Type}}) string {
+return fmt.Sprint("{{snake .GoName}}"{{range pkColumns .}}, ":", v.{{.GoName}}{{end}})
+}
+
+var {{.GoName}}Columns = map[string]string{
+{{- range .Columns}}
+"{{.Name}}": "{{goType .Type}} {{.SQLType}}",
+{{- end}}
+}
+
+// {{quote .Name}} {{camel "user_id"}}
+{{end}}`
+	tmpl := template.Must(template.New("cache_gen.go").Funcs(TemplateFuncs()).Parse(text))
+
+	var buf bytes.Buffer
+	if err := m.GenerateTemplate(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `package schema
+
+import (
+	"fmt"
+	"time"
+)
+
+func Template1CacheKey(v *Template1) string {
+	return fmt.Sprint("template1", ":", v.UserID, ":", v.Name)
+}
+
+var Template1Columns = map[string]string{
+	"user_id":    "int64 BIGINT",
+	"name":       "string VARCHAR(191)",
+	"nickname":   "*string VARCHAR(191)",
+	"created_at": "time.Time DATETIME(6)",
+}
+
+// ` + "`template1`" + ` UserID
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: want %q, got %q", want, got)
+	}
+}
+
+func TestMaker_GenerateTemplate_NotGo(t *testing.T) {
+	m, err := New(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddStructs(&Template1{})
+
+	// the output is not formatted.
+	tmpl := template.Must(template.New("tables.txt").Funcs(TemplateFuncs()).Parse(`{{range .Tables}}{{.Name}}  {{len .Columns}}{{end}}`))
+
+	var buf bytes.Buffer
+	if err := m.GenerateTemplate(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "template1  4"; got != want {
+		t.Errorf("unexpected output: want %q, got %q", want, got)
+	}
+}
+
+func TestMaker_GenerateTemplate_Error(t *testing.T) {
+	m, err := New(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddStructs(&Template1{})
+
+	tmpl := template.Must(template.New("broken.go").Funcs(TemplateFuncs()).Parse(`package {{.PackageName}} func {`))
+
+	var buf bytes.Buffer
+	err = m.GenerateTemplate(&buf, tmpl)
+	if err == nil {
+		t.Fatal("want error, but not")
+	}
+	if !strings.HasPrefix(err.Error(), "myddlmaker: failed to format the output of the template broken.go:") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package schema
+
+import (
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Item struct {
+	ID   uint64 `ddl:",auto"`
+	Name string
+}
+
+func (*Item) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+type Note struct {
+	ID   *int32 `ddl:",auto"`
+	Text string
+}
+
+func (*Note) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
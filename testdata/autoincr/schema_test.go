@@ -0,0 +1,110 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestInsertItem(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// multiple batches
+	items := []*Item{}
+	for i := 0; i < 100; i++ {
+		items = append(items, &Item{Name: fmt.Sprintf("item%d", i)})
+	}
+	if err := InsertItem(ctx, db, items...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	for _, item := range items {
+		if item.ID == 0 {
+			t.Errorf("%s: the id is not assigned", item.Name)
+			continue
+		}
+		got, err := SelectItem(ctx, db, &Item{ID: item.ID})
+		if err != nil {
+			t.Errorf("%s: failed to select: %v", item.Name, err)
+			continue
+		}
+		if got.Name != item.Name {
+			t.Errorf("id %d: want %s, got %s", item.ID, item.Name, got.Name)
+		}
+	}
+}
+
+func TestInsertNote(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	notes := []*Note{{Text: "foo"}, {Text: "bar"}}
+	if err := InsertNote(ctx, db, notes...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	for _, note := range notes {
+		if note.ID == nil {
+			t.Errorf("%s: the id is not assigned", note.Text)
+			continue
+		}
+		got, err := SelectNote(ctx, db, &Note{ID: note.ID})
+		if err != nil {
+			t.Errorf("%s: failed to select: %v", note.Text, err)
+			continue
+		}
+		if got.Text != note.Text {
+			t.Errorf("id %d: want %s, got %s", *note.ID, note.Text, got.Text)
+		}
+	}
+}
+
+func TestSelectItemByPrimaryKeys(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	items := []*Item{{Name: "foo"}, {Name: "bar"}, {Name: "baz"}}
+	if err := InsertItem(ctx, db, items...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	m, err := SelectItemMapByPrimaryKeys(ctx, db, items...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if v := m[item.ID]; v == nil || v.Name != item.Name {
+			t.Errorf("id %d: unexpected item: %#v", item.ID, v)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package schema
+
+import (
+	"github.com/shogo82148/myddlmaker"
+)
+
+type User struct {
+	ID   int64 `ddl:",auto"`
+	Name string
+}
+
+func (*User) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*User) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		myddlmaker.NewUniqueIndex("uniq_name", "name"),
+	}
+}
@@ -0,0 +1,266 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeExecer struct {
+	queries []string
+}
+
+func (e *fakeExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (e *fakeExecer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	panic("not implemented")
+}
+
+type routeKey struct{}
+
+func setCommenter(t *testing.T) {
+	SetCommenter(func(ctx context.Context) map[string]string {
+		route, _ := ctx.Value(routeKey{}).(string)
+		return map[string]string{
+			"app":   "myapp",
+			"route": route,
+		}
+	})
+	t.Cleanup(func() { SetCommenter(nil) })
+}
+
+func TestSetCommenter(t *testing.T) {
+	e := &fakeExecer{}
+	ctx := context.WithValue(context.Background(), routeKey{}, "/users/{id}")
+
+	// without the commenter.
+	if err := DeleteUser(ctx, e, &User{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	setCommenter(t)
+	if err := DeleteUser(ctx, e, &User{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the untrusted values can't break out of the comment.
+	ctx = context.WithValue(context.Background(), routeKey{}, "x' */ DROP TABLE `user`; /*")
+	if err := DeleteUser(ctx, e, &User{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	const q = "DELETE FROM `user` WHERE `id` IN (?)"
+	want := []string{
+		q,
+		"/*app='myapp',op='delete',route='%2Fusers%2F%7Bid%7D',table='user'*/ " + q,
+		"/*app='myapp',op='delete',route='x%27%20%2A%2F%20DROP%20TABLE%20%60user%60%3B%20%2F%2A',table='user'*/ " + q,
+	}
+	if len(e.queries) != len(want) {
+		t.Fatalf("unexpected queries: %q", e.queries)
+	}
+	for i := range want {
+		if e.queries[i] != want[i] {
+			t.Errorf("unexpected query: want %q, got %q", want[i], e.queries[i])
+		}
+	}
+}
+
+func TestSetCommenter_DB(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ctx = context.WithValue(ctx, routeKey{}, "/users")
+	setCommenter(t)
+
+	user := &User{Name: "alice"}
+	if err := InsertUser(ctx, db, user); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	user.Name = "bob"
+	if err := UpdateUser(ctx, db, user); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	got, err := SelectUserByUniqName(ctx, db, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("unexpected id: want %d, got %d", user.ID, got.ID)
+	}
+}
+
+// recordDriver is a database/sql driver that records the queries.
+// The queries return no rows.
+type recordDriver struct {
+	mu       sync.Mutex
+	queries  []string
+	prepares []string
+}
+
+func (d *recordDriver) Open(name string) (driver.Conn, error) {
+	return &recordConn{d: d}, nil
+}
+
+func (d *recordDriver) record(query string, prepare bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if prepare {
+		d.prepares = append(d.prepares, query)
+	} else {
+		d.queries = append(d.queries, query)
+	}
+}
+
+type recordConn struct {
+	d *recordDriver
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.record(query, true)
+	return &recordStmt{c: c, query: query}, nil
+}
+
+func (c *recordConn) Close() error              { return nil }
+func (c *recordConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query, false)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(query, false)
+	return emptyRows{}, nil
+}
+
+type recordStmt struct {
+	c     *recordConn
+	query string
+}
+
+func (s *recordStmt) Close() error  { return nil }
+func (s *recordStmt) NumInput() int { return -1 }
+
+func (s *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.record(s.query, false)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.record(s.query, false)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"id", "name"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+var testDriver = &recordDriver{}
+
+func init() {
+	sql.Register("commenter-record", testDriver)
+}
+
+type recorder struct {
+	queries []string
+}
+
+func (r *recorder) Before(ctx context.Context, info QueryInfo) context.Context {
+	return ctx
+}
+
+func (r *recorder) After(ctx context.Context, info QueryInfo, err error) {
+	r.queries = append(r.queries, info.Query)
+}
+
+func TestSetCommenter_Queries(t *testing.T) {
+	db, err := sql.Open("commenter-record", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	r := &recorder{}
+	SetHooks(r)
+	t.Cleanup(func() { SetHooks(nil) })
+	setCommenter(t)
+
+	q := NewQueries(db)
+	defer q.Close()
+
+	for _, route := range []string{"/a", "/b", "/c"} {
+		ctx := context.WithValue(context.Background(), routeKey{}, route)
+		if err := q.DeleteUser(ctx, &User{ID: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := q.SelectUserByUniqName(ctx, "alice"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("want sql.ErrNoRows, got %v", err)
+		}
+	}
+
+	// the annotated statements are neither prepared nor cached.
+	if len(testDriver.prepares) != 0 {
+		t.Errorf("unexpected prepares: %q", testDriver.prepares)
+	}
+	if len(q.stmts.stmts) != 0 {
+		t.Errorf("unexpected cached statements: %d", len(q.stmts.stmts))
+	}
+
+	// the hooks see the annotated queries.
+	if len(testDriver.queries) != 6 || len(r.queries) != 6 {
+		t.Fatalf("unexpected queries: %q, %q", testDriver.queries, r.queries)
+	}
+	for i, query := range testDriver.queries {
+		if !strings.HasPrefix(query, "/*") {
+			t.Errorf("the query is not annotated: %q", query)
+		}
+		if r.queries[i] != query {
+			t.Errorf("unexpected query in the hook: want %q, got %q", query, r.queries[i])
+		}
+	}
+
+	// the statements are cached without the commenter.
+	SetCommenter(nil)
+	if err := q.DeleteUser(context.Background(), &User{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.stmts.stmts) != 1 {
+		t.Errorf("unexpected cached statements: %d", len(q.stmts.stmts))
+	}
+}
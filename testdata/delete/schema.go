@@ -0,0 +1,23 @@
+package schema
+
+import (
+	"github.com/shogo82148/myddlmaker"
+)
+
+type User struct {
+	ID   int32 `ddl:",auto"`
+	Name string
+}
+
+func (*User) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+type Membership struct {
+	GroupID int32
+	UserID  int32
+}
+
+func (*Membership) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("group_id", "user_id")
+}
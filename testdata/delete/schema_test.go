@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDeleteUser(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	users := []*User{}
+	for i := 0; i < 100; i++ {
+		users = append(users, &User{ID: int32(i + 1), Name: "user"})
+	}
+	if err := InsertUser(ctx, db, users...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	if err := DeleteUser(ctx, db, &User{ID: 1}); err != nil {
+		t.Errorf("failed to delete: %v", err)
+	}
+	if _, err := SelectUser(ctx, db, &User{ID: 1}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, but got %v", err)
+	}
+
+	// multiple delete, including a row that doesn't exist.
+	n, err := DeleteUserRowsAffected(ctx, db, users...)
+	if err != nil {
+		t.Errorf("failed to delete: %v", err)
+	}
+	if n != 99 {
+		t.Errorf("unexpected rows affected: want %d, got %d", 99, n)
+	}
+
+	all, err := SelectAllUser(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Errorf("unexpected count: want %d, got %d", 0, len(all))
+	}
+}
+
+func TestDeleteMembership(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	members := []*Membership{}
+	for i := 0; i < 100; i++ {
+		members = append(members, &Membership{GroupID: int32(i % 3), UserID: int32(i)})
+	}
+	if err := InsertMembership(ctx, db, members...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	n, err := DeleteMembershipRowsAffected(ctx, db, members[:50]...)
+	if err != nil {
+		t.Errorf("failed to delete: %v", err)
+	}
+	if n != 50 {
+		t.Errorf("unexpected rows affected: want %d, got %d", 50, n)
+	}
+
+	// the composite key must match exactly.
+	if err := DeleteMembership(ctx, db, &Membership{GroupID: 1, UserID: 51}); err != nil {
+		t.Errorf("failed to delete: %v", err)
+	}
+	if _, err := SelectMembership(ctx, db, &Membership{GroupID: 0, UserID: 51}); err != nil {
+		t.Errorf("failed to select: %v", err)
+	}
+
+	all, err := SelectAllMembership(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 50 {
+		t.Errorf("unexpected count: want %d, got %d", 50, len(all))
+	}
+}
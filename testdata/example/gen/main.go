@@ -22,7 +22,7 @@ func main() {
 	}
 
 	// generate Go source code for basic SQL operations
-	// such as INSERT, SELECT, and UPDATE.
+	// such as INSERT, SELECT, UPDATE, and DELETE.
 	if err := m.GenerateGoFile(); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type User struct {
+	ID        int64 `ddl:",auto"`
+	Email     string
+	Nickname  sql.NullString `ddl:",null"`
+	Version   int64          `ddl:",version"`
+	CreatedAt time.Time      `ddl:",created"`
+	UpdatedAt time.Time      `ddl:",updated"`
+}
+
+func (*User) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*User) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		myddlmaker.NewUniqueIndex("uniq_email", "email"),
+		myddlmaker.NewUniqueIndex("uniq_nickname", "nickname"),
+	}
+}
+
+type Post struct {
+	UserID    int64
+	Slug      string
+	Title     string
+	DeletedAt *time.Time `ddl:",null,softdelete"`
+}
+
+func (*Post) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("user_id", "slug")
+}
+
+// Event has a time and a pointer in the primary key.
+type Event struct {
+	StartAt time.Time
+	Code    *string
+	Name    string
+}
+
+func (*Event) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("start_at", "code")
+}
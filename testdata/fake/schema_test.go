@@ -0,0 +1,297 @@
+//go:build fake
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFakeDB_User(t *testing.T) {
+	ctx := context.Background()
+	db := NewFakeDB()
+
+	users := []*User{
+		{Email: "alice@example.com"},
+		{Email: "bob@example.com", Nickname: sql.NullString{String: "bob", Valid: true}},
+		{Email: "carol@example.com"},
+	}
+	if err := db.InsertUser(ctx, users...); err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range users {
+		if u.ID != int64(i+1) {
+			t.Errorf("unexpected id: want %d, got %d", i+1, u.ID)
+		}
+		if u.CreatedAt.IsZero() {
+			t.Error("created_at is not set")
+		}
+	}
+
+	// the unique indexes are enforced, but NULL values don't conflict.
+	err := db.InsertUser(ctx, &User{Email: "alice@example.com"})
+	if !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Errorf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+	err = db.InsertUser(ctx, &User{ID: 1, Email: "dave@example.com"})
+	if !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Errorf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+
+	got, err := db.SelectUser(ctx, &User{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "bob@example.com" {
+		t.Errorf("unexpected email: %q", got.Email)
+	}
+	if _, err := db.SelectUser(ctx, &User{ID: 100}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+	got, err = db.SelectUserByUniqNickname(ctx, sql.NullString{String: "bob", Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 2 {
+		t.Errorf("unexpected id: want %d, got %d", 2, got.ID)
+	}
+	if _, err := db.SelectUserByUniqNickname(ctx, sql.NullString{}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+
+	// the version is checked and incremented.
+	got.Email = "robert@example.com"
+	if err := db.UpdateUser(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 1 {
+		t.Errorf("unexpected version: want %d, got %d", 1, got.Version)
+	}
+	stale := *got
+	stale.Version = 0
+	var errStale *ErrStaleObject
+	if err := db.UpdateUser(ctx, &stale); !errors.As(err, &errStale) {
+		t.Errorf("want ErrStaleObject, got %v", err)
+	}
+	got.Email = "alice@example.com"
+	if err := db.UpdateUser(ctx, got); !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Errorf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+
+	list, err := db.SelectUserByPrimaryKeys(ctx, &User{ID: 1}, &User{ID: 3}, &User{ID: 3}, &User{ID: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(list))
+	}
+
+	n, err := db.DeleteUserRowsAffected(ctx, users[0], &User{ID: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("unexpected rows affected: want %d, got %d", 1, n)
+	}
+	all, err := db.SelectAllUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].ID != 2 || all[1].ID != 3 {
+		t.Errorf("unexpected rows: %v", all)
+	}
+
+	// the AUTO_INCREMENT value follows the explicit value.
+	user := &User{ID: 10, Email: "erin@example.com"}
+	if err := db.InsertUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	user = &User{Email: "frank@example.com"}
+	if err := db.InsertUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 11 {
+		t.Errorf("unexpected id: want %d, got %d", 11, user.ID)
+	}
+}
+
+func TestFakeDB_Post(t *testing.T) {
+	ctx := context.Background()
+	db := NewFakeDB()
+
+	posts := []*Post{
+		{UserID: 2, Slug: "hello", Title: "Hello"},
+		{UserID: 1, Slug: "world", Title: "World"},
+		{UserID: 1, Slug: "foo", Title: "Foo"},
+	}
+	if err := db.InsertPost(ctx, posts...); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := db.SelectAllPost(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slugs []string
+	for _, p := range all {
+		slugs = append(slugs, p.Slug)
+	}
+	if len(slugs) != 3 || slugs[0] != "foo" || slugs[1] != "world" || slugs[2] != "hello" {
+		t.Errorf("unexpected order: %v", slugs)
+	}
+
+	// the soft-deleted rows are not returned, but they still occupy the primary key.
+	if err := db.DeletePost(ctx, posts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SelectPost(ctx, posts[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+	if err := db.InsertPost(ctx, &Post{UserID: 2, Slug: "hello"}); !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Errorf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+	n, err := db.DeletePostRowsAffected(ctx, posts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("unexpected rows affected: want %d, got %d", 0, n)
+	}
+
+	if err := db.HardDeletePost(ctx, posts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InsertPost(ctx, &Post{UserID: 2, Slug: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeDB_Now(t *testing.T) {
+	now := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	Now = func() time.Time { return now }
+	defer func() { Now = time.Now }()
+
+	ctx := context.Background()
+	db := NewFakeDB()
+	user := &User{Email: "alice@example.com"}
+	if err := db.InsertUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.SelectUser(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(now) || !got.UpdatedAt.Equal(now) {
+		t.Errorf("unexpected timestamps: %v, %v", got.CreatedAt, got.UpdatedAt)
+	}
+}
+
+func TestFakeDB_SoftDeleteNow(t *testing.T) {
+	now := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	Now = func() time.Time { return now }
+	defer func() { Now = time.Now }()
+
+	ctx := context.Background()
+	db := NewFakeDB()
+	post := &Post{UserID: 1, Slug: "hello"}
+	if err := db.InsertPost(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeletePost(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+	got := db.tablePost.rows[fakePostKey{UserID: 1, Slug: "hello"}].v
+	if got.DeletedAt == nil || !got.DeletedAt.Equal(now) {
+		t.Errorf("unexpected deleted_at: %v", got.DeletedAt)
+	}
+}
+
+func TestFakeDB_CaseInsensitive(t *testing.T) {
+	ctx := context.Background()
+	db := NewFakeDB()
+
+	// the default collation is case-insensitive.
+	if err := db.InsertUser(ctx, &User{Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InsertUser(ctx, &User{Email: "Alice@Example.com"}); !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Errorf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+	got, err := db.SelectUserByUniqEmail(ctx, "ALICE@EXAMPLE.COM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "alice@example.com" {
+		t.Errorf("unexpected email: %q", got.Email)
+	}
+}
+
+func TestFakeDB_InsertAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	db := NewFakeDB()
+
+	if err := db.InsertUser(ctx, &User{Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second value conflicts, so the first one is not inserted too.
+	users := []*User{{Email: "bob@example.com"}, {Email: "alice@example.com"}}
+	if err := db.InsertUser(ctx, users...); !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Fatalf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+	if users[0].ID != 0 {
+		t.Errorf("the id is assigned: %d", users[0].ID)
+	}
+	all, err := db.SelectAllUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Errorf("unexpected rows: %#v", all)
+	}
+
+	// the AUTO_INCREMENT counter isn't advanced by the failed insert.
+	user := &User{Email: "bob@example.com"}
+	if err := db.InsertUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 2 {
+		t.Errorf("unexpected id: want %d, got %d", 2, user.ID)
+	}
+}
+
+func TestFakeDB_Event(t *testing.T) {
+	ctx := context.Background()
+	db := NewFakeDB()
+
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	startAt := time.Date(2024, time.January, 2, 3, 4, 5, 0, jst)
+	code := "a"
+	if err := db.InsertEvent(ctx, &Event{StartAt: startAt, Code: &code, Name: "party"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same instant in another location matches.
+	other := "a"
+	got, err := db.SelectEvent(ctx, &Event{StartAt: startAt.UTC(), Code: &other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "party" {
+		t.Errorf("unexpected name: %q", got.Name)
+	}
+	if err := db.InsertEvent(ctx, &Event{StartAt: startAt.UTC(), Code: &other}); !errors.Is(err, ErrFakeDuplicateEntry) {
+		t.Errorf("want ErrFakeDuplicateEntry, got %v", err)
+	}
+
+	// NULL never matches, and can't be inserted.
+	if _, err := db.SelectEvent(ctx, &Event{StartAt: startAt}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+	if err := db.InsertEvent(ctx, &Event{StartAt: startAt}); err == nil {
+		t.Error("want error, got nil")
+	}
+}
@@ -0,0 +1,201 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type recorder struct {
+	infos []QueryInfo
+	errs  []error
+}
+
+func (r *recorder) Before(ctx context.Context, info QueryInfo) context.Context {
+	return ctx
+}
+
+func (r *recorder) After(ctx context.Context, info QueryInfo, err error) {
+	r.infos = append(r.infos, info)
+	r.errs = append(r.errs, err)
+}
+
+func setHooks(t *testing.T) *recorder {
+	r := &recorder{}
+	SetHooks(r)
+	t.Cleanup(func() { SetHooks(nil) })
+	return r
+}
+
+type fakeExecer struct {
+	queries []string
+}
+
+func (e *fakeExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (e *fakeExecer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	panic("not implemented")
+}
+
+func TestHooks_Exec(t *testing.T) {
+	r := setHooks(t)
+	e := &fakeExecer{}
+	ctx := context.Background()
+
+	if err := DeletePost(ctx, e, &Post{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpsertUser(ctx, e, &User{Name: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.infos) != 2 {
+		t.Fatalf("unexpected count: want %d, got %d", 2, len(r.infos))
+	}
+	want := QueryInfo{Table: "post", Operation: "delete", Query: e.queries[0], RowCount: 1}
+	if r.infos[0] != want {
+		t.Errorf("unexpected info: want %#v, got %#v", want, r.infos[0])
+	}
+	want = QueryInfo{Table: "user", Operation: "upsert", Query: e.queries[1], RowCount: 1}
+	if r.infos[1] != want {
+		t.Errorf("unexpected info: want %#v, got %#v", want, r.infos[1])
+	}
+}
+
+func TestHooks_Query(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	user := &User{Name: "bob"}
+	if err := InsertUser(ctx, db, user); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	posts := []*Post{
+		{UserID: user.ID, Title: "Hello"},
+		{UserID: user.ID, Title: "World"},
+	}
+	if err := InsertPost(ctx, db, posts...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	r := setHooks(t)
+	if _, err := SelectUserByUniqName(ctx, db, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SelectPostsByUser(ctx, db, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SelectUserByUniqName(ctx, db, "carol"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+
+	if len(r.infos) != 3 {
+		t.Fatalf("unexpected count: want %d, got %d", 3, len(r.infos))
+	}
+	for i, want := range []int64{1, 2, 0} {
+		if r.infos[i].Operation != "select" {
+			t.Errorf("unexpected operation: %q", r.infos[i].Operation)
+		}
+		if r.infos[i].RowCount != want {
+			t.Errorf("unexpected row count: want %d, got %d", want, r.infos[i].RowCount)
+		}
+	}
+	if !errors.Is(r.errs[2], sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", r.errs[2])
+	}
+}
+
+// badDriver is a database/sql driver that returns a row
+// whose id can't be scanned into int64.
+type badDriver struct{}
+
+func (badDriver) Open(name string) (driver.Conn, error) {
+	return badConn{}, nil
+}
+
+type badConn struct{}
+
+func (badConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (badConn) Close() error                              { return nil }
+func (badConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (badConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &badRows{}, nil
+}
+
+type badRows struct {
+	done bool
+}
+
+func (*badRows) Columns() []string { return []string{"id", "user_id", "title", "deleted_at"} }
+func (*badRows) Close() error      { return nil }
+
+func (r *badRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = "not a number"
+	dest[1] = int64(1)
+	dest[2] = "Hello"
+	dest[3] = nil
+	return nil
+}
+
+func init() {
+	sql.Register("hooks-bad", badDriver{})
+}
+
+func TestHooks_ScanError(t *testing.T) {
+	db, err := sql.Open("hooks-bad", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	r := setHooks(t)
+	_, err = SelectPostsByUser(context.Background(), db, &User{ID: 1})
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+
+	if len(r.errs) != 1 {
+		t.Fatalf("unexpected count: want %d, got %d", 1, len(r.errs))
+	}
+	if r.errs[0] != err {
+		t.Errorf("the hook didn't get the scan error: want %v, got %v", err, r.errs[0])
+	}
+}
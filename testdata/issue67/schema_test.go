@@ -36,6 +36,9 @@ func TestFoo1(t *testing.T) {
 	if err := InsertUser(ctx, db, u1); err != nil {
 		t.Errorf("failed to insert: %v", err)
 	}
+	if u1.ID != 1 {
+		t.Errorf("want 1, but got %d", u1.ID)
+	}
 
 	all, err := SelectAllUser(ctx, db)
 	if err != nil {
@@ -0,0 +1,15 @@
+package schema
+
+import (
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Score struct {
+	GameID int32
+	UserID int32
+	Score  int64
+}
+
+func (*Score) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("game_id", "user_id")
+}
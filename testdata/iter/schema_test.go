@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestIterAllScore(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	scores := []*Score{}
+	for i := 0; i < 100; i++ {
+		scores = append(scores, &Score{GameID: int32(i % 7), UserID: int32(i), Score: int64(i)})
+	}
+	if err := InsertScore(ctx, db, scores...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	t.Run("IterAllScore", func(t *testing.T) {
+		var count int
+		var prev *Score
+		for v, err := range IterAllScore(ctx, db) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prev != nil && (v.GameID < prev.GameID || (v.GameID == prev.GameID && v.UserID <= prev.UserID)) {
+				t.Errorf("unexpected order: %#v, %#v", prev, v)
+			}
+			prev = v
+			count++
+		}
+		if count != 100 {
+			t.Errorf("unexpected count: want %d, got %d", 100, count)
+		}
+	})
+
+	t.Run("IterAllScore stops early", func(t *testing.T) {
+		var count int
+		for _, err := range IterAllScore(ctx, db) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			count++
+			if count == 10 {
+				break
+			}
+		}
+
+		// the connection is released, so the next query succeeds.
+		db.SetMaxOpenConns(1)
+		defer db.SetMaxOpenConns(0)
+		if _, err := SelectAllScore(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	for _, size := range []int{1, 7, 10, 100, 1000} {
+		var count int
+		var prev *Score
+		for v, err := range IterAllScoreBatched(ctx, db, size) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prev != nil && (v.GameID < prev.GameID || (v.GameID == prev.GameID && v.UserID <= prev.UserID)) {
+				t.Errorf("batch size %d: unexpected order: %#v, %#v", size, prev, v)
+			}
+			prev = v
+			count++
+		}
+		if count != 100 {
+			t.Errorf("batch size %d: unexpected count: want %d, got %d", size, 100, count)
+		}
+	}
+}
+
+func TestIterAllScoreBatched_InvalidBatchSize(t *testing.T) {
+	for _, err := range IterAllScoreBatched(context.Background(), nil, 0) {
+		if err == nil {
+			t.Error("want some error, got nil")
+		}
+	}
+}
+
+func TestSelectScoreByPrimaryKeys(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	scores := []*Score{}
+	for i := 0; i < 10; i++ {
+		scores = append(scores, &Score{GameID: 100, UserID: int32(i), Score: int64(i * 10)})
+	}
+	if err := InsertScore(ctx, db, scores...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	if got, err := SelectScoreByPrimaryKeys(ctx, db); err != nil || len(got) != 0 {
+		t.Errorf("unexpected result: %v, %v", got, err)
+	}
+
+	keys := []*Score{
+		{GameID: 100, UserID: 1},
+		{GameID: 100, UserID: 3},
+		{GameID: 101, UserID: 3}, // not found
+	}
+	got, err := SelectScoreByPrimaryKeys(ctx, db, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(got))
+	}
+
+	m, err := SelectScoreMapByPrimaryKeys(ctx, db, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(m))
+	}
+	if v := m[ScorePrimaryKey{GameID: 100, UserID: 3}]; v == nil || v.Score != 30 {
+		t.Errorf("unexpected score: %#v", v)
+	}
+}
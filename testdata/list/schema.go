@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Event struct {
+	ID        int64 `ddl:",auto"`
+	UserID    int64
+	Kind      string
+	CreatedAt time.Time
+	Note      *string `ddl:",null"`
+}
+
+func (*Event) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Event) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_user_id_created_at", "user_id", "created_at").DESC("created_at"),
+		myddlmaker.NewIndex("idx_kind", "kind"),
+
+		// no list functions are generated for these indexes.
+		myddlmaker.NewIndex("idx_note", "note"),
+		myddlmaker.NewIndex("idx_invisible", "user_id").Invisible(),
+	}
+}
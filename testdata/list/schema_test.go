@@ -0,0 +1,194 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestListEvent(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// some events have the same created_at.
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []*Event{}
+	for i := 0; i < 100; i++ {
+		events = append(events, &Event{
+			UserID:    int64(i % 2),
+			Kind:      []string{"a", "b", "c"}[i%3],
+			CreatedAt: base.Add(time.Duration(i/4) * time.Second),
+		})
+	}
+	if err := InsertEvent(ctx, db, events...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	t.Run("ListEventByIdxUserIDCreatedAt", func(t *testing.T) {
+		var all []*Event
+		var cursor *ListEventByIdxUserIDCreatedAtCursor
+		for {
+			page, next, err := ListEventByIdxUserIDCreatedAt(ctx, db, 1, cursor, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, page...)
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if len(all) != 50 {
+			t.Fatalf("unexpected count: want %d, got %d", 50, len(all))
+		}
+		for i, v := range all {
+			if v.UserID != 1 {
+				t.Errorf("unexpected user id: %d", v.UserID)
+			}
+			if i == 0 {
+				continue
+			}
+			prev := all[i-1]
+			if v.CreatedAt.After(prev.CreatedAt) || (v.CreatedAt.Equal(prev.CreatedAt) && v.ID <= prev.ID) {
+				t.Errorf("unexpected order: %#v, %#v", prev, v)
+			}
+		}
+	})
+
+	t.Run("ListEventByIdxUserIDCreatedAtPrefix0", func(t *testing.T) {
+		var all []*Event
+		var cursor *ListEventByIdxUserIDCreatedAtPrefix0Cursor
+		for {
+			page, next, err := ListEventByIdxUserIDCreatedAtPrefix0(ctx, db, cursor, 9)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, page...)
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if len(all) != 100 {
+			t.Fatalf("unexpected count: want %d, got %d", 100, len(all))
+		}
+		for i := 1; i < len(all); i++ {
+			prev, v := all[i-1], all[i]
+			if v.UserID < prev.UserID {
+				t.Errorf("unexpected order: %#v, %#v", prev, v)
+			}
+			if v.UserID == prev.UserID && (v.CreatedAt.After(prev.CreatedAt) || (v.CreatedAt.Equal(prev.CreatedAt) && v.ID <= prev.ID)) {
+				t.Errorf("unexpected order: %#v, %#v", prev, v)
+			}
+		}
+	})
+
+	t.Run("the last page is full", func(t *testing.T) {
+		// 50 rows are returned in 5 pages, and no empty page follows.
+		var pages int
+		var cursor *ListEventByIdxUserIDCreatedAtCursor
+		for {
+			page, next, err := ListEventByIdxUserIDCreatedAt(ctx, db, 0, cursor, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) != 10 {
+				t.Errorf("unexpected page size: want %d, got %d", 10, len(page))
+			}
+			pages++
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if pages != 5 {
+			t.Errorf("unexpected page count: want %d, got %d", 5, pages)
+		}
+	})
+
+	t.Run("ListEventByIdxKind", func(t *testing.T) {
+		var all []*Event
+		var cursor *ListEventByIdxKindCursor
+		for {
+			page, next, err := ListEventByIdxKind(ctx, db, cursor, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, page...)
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if len(all) != 100 {
+			t.Fatalf("unexpected count: want %d, got %d", 100, len(all))
+		}
+		for i := 1; i < len(all); i++ {
+			prev, v := all[i-1], all[i]
+			if v.Kind < prev.Kind || (v.Kind == prev.Kind && v.ID <= prev.ID) {
+				t.Errorf("unexpected order: %#v, %#v", prev, v)
+			}
+		}
+	})
+
+	t.Run("no filesort", func(t *testing.T) {
+		queries := []string{
+			"EXPLAIN SELECT `id` FROM `event` WHERE `user_id` = 1 AND (`created_at` < NOW() OR (`created_at` = NOW() AND `id` > 1)) ORDER BY `created_at` DESC, `id` ASC LIMIT 10",
+			"EXPLAIN SELECT `id` FROM `event` WHERE (`kind` > 'a' OR (`kind` = 'a' AND `id` > 1)) ORDER BY `kind` ASC, `id` ASC LIMIT 10",
+		}
+		for _, q := range queries {
+			rows, err := db.QueryContext(ctx, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cols, err := rows.Columns()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for rows.Next() {
+				values := make([]sql.NullString, len(cols))
+				ptrs := make([]any, len(cols))
+				for i := range values {
+					ptrs[i] = &values[i]
+				}
+				if err := rows.Scan(ptrs...); err != nil {
+					t.Fatal(err)
+				}
+				for i, col := range cols {
+					if col == "Extra" && strings.Contains(values[i].String, "filesort") {
+						t.Errorf("%s: unexpected filesort: %s", q, values[i].String)
+					}
+				}
+			}
+			rows.Close()
+		}
+	})
+}
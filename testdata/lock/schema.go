@@ -0,0 +1,24 @@
+package schema
+
+import (
+	"database/sql"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Job struct {
+	ID        int64 `ddl:",auto"`
+	Name      string
+	Status    string
+	DeletedAt sql.NullTime `ddl:",null,softdelete"`
+}
+
+func (*Job) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Job) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		myddlmaker.NewUniqueIndex("uniq_name", "name"),
+	}
+}
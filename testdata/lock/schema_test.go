@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSelectJobWithLock(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	job := &Job{Name: "job1", Status: "waiting"}
+	if err := InsertJob(ctx, db, job); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	tx1, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx1.Rollback()
+
+	got, err := SelectJobWithLock(ctx, tx1, job, LockForUpdate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "job1" {
+		t.Errorf("unexpected name: want %q, got %q", "job1", got.Name)
+	}
+
+	tx2, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx2.Rollback()
+
+	// the row is locked by tx1.
+	if _, err := SelectJobByUniqNameWithLock(ctx, tx2, "job1", LockForUpdateSkipLocked); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+	if _, err := SelectJobByUniqNameWithLock(ctx, tx2, "job1", LockForShareNowait); err == nil {
+		t.Error("want error, got nil")
+	}
+
+	if _, err := SelectJobWithLock(ctx, tx1, job, LockMode("LOCK IN SHARE MODE")); err == nil {
+		t.Error("want error, got nil")
+	}
+}
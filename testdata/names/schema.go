@@ -0,0 +1,22 @@
+package schema
+
+import (
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Account struct {
+	ID     int64  `ddl:",auto"`
+	NameJP string `ddl:"name_jp"`
+	Email  string
+
+	// AllColumns doesn't conflict with AccountAllColumns.
+	AllColumns string
+}
+
+func (*Account) Table() string {
+	return "accounts"
+}
+
+func (*Account) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
@@ -0,0 +1,23 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestAccountNames(t *testing.T) {
+	if AccountTable != "accounts" {
+		t.Errorf("unexpected table name: %q", AccountTable)
+	}
+
+	if got, want := AccountColumnNameJP.Quoted(), "`name_jp`"; got != want {
+		t.Errorf("unexpected quoted name: want %q, got %q", want, got)
+	}
+
+	var names []string
+	for _, c := range AccountAllColumns {
+		names = append(names, string(c))
+	}
+	if len(names) != 4 || names[0] != "id" || names[1] != "name_jp" || names[2] != "email" || names[3] != "all_columns" {
+		t.Errorf("unexpected columns: %v", names)
+	}
+}
@@ -0,0 +1,24 @@
+package schema
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Profile struct {
+	ID        int32 `ddl:",auto"`
+	Name      string
+	Age       int32
+	Bio       *string `ddl:",null"`
+	Avatar    []byte
+	Settings  json.RawMessage
+	Nickname  sql.NullString `ddl:",null"`
+	UpdatedAt time.Time
+}
+
+func (*Profile) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
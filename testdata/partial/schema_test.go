@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUpdateProfileColumns(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &Profile{
+		Name:      "alice",
+		Age:       20,
+		Avatar:    []byte{},
+		Settings:  json.RawMessage(`{}`),
+		UpdatedAt: now,
+	}
+	if err := InsertProfile(ctx, db, p); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	// another writer updates the age.
+	if err := UpdateProfileColumns(ctx, db, &Profile{ID: p.ID, Age: 21}, ProfileColumnAge); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	// the name is updated, but the age is kept.
+	p.Name = "ALICE"
+	if err := UpdateProfileColumns(ctx, db, p, ProfileColumnName); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	got, err := SelectProfile(ctx, db, &Profile{ID: p.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "ALICE" || got.Age != 21 {
+		t.Errorf("unexpected profile: %#v", got)
+	}
+
+	// the primary key can't be updated.
+	if err := UpdateProfileColumns(ctx, db, p, ProfileColumnID); err == nil {
+		t.Error("want some error, got nil")
+	}
+}
+
+func TestUpdateProfileDiff(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &Profile{
+		Name:      "bob",
+		Age:       30,
+		Avatar:    []byte{},
+		Settings:  json.RawMessage(`{}`),
+		UpdatedAt: now,
+	}
+	if err := InsertProfile(ctx, db, p); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	original, err := SelectProfile(ctx, db, &Profile{ID: p.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another writer updates the age.
+	if err := UpdateProfileColumns(ctx, db, &Profile{ID: p.ID, Age: 31}, ProfileColumnAge); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	bio := "hello"
+	modified := *original
+	modified.Bio = &bio
+	modified.Avatar = []byte{1, 2, 3}
+	modified.UpdatedAt = now.In(time.FixedZone("Asia/Tokyo", 9*60*60)) // same instant
+	if err := UpdateProfileDiff(ctx, db, original, &modified); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	got, err := SelectProfile(ctx, db, &Profile{ID: p.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Age != 31 {
+		t.Errorf("unexpected age: want %d, got %d", 31, got.Age)
+	}
+	if got.Bio == nil || *got.Bio != "hello" {
+		t.Errorf("unexpected bio: %v", got.Bio)
+	}
+	if string(got.Avatar) != "\x01\x02\x03" {
+		t.Errorf("unexpected avatar: %v", got.Avatar)
+	}
+
+	// nothing is changed.
+	if err := UpdateProfileDiff(ctx, db, got, got); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQueries(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	q := NewQueries(db)
+	defer q.Close()
+
+	users := []*User{{Name: "alice", Age: 20}, {Name: "bob", Age: 30}}
+	if err := q.InsertUser(ctx, users...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	// the statements are reused.
+	for i := 0; i < 3; i++ {
+		got, err := q.SelectUserByIdxName(ctx, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != users[0].ID {
+			t.Errorf("unexpected id: want %d, got %d", users[0].ID, got.ID)
+		}
+	}
+
+	users[0].Age++
+	if err := q.UpdateUser(ctx, users[0]); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	// rollback
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.WithTx(tx).DeleteUser(ctx, users[0]); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := q.SelectUser(ctx, users[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Age != 21 {
+		t.Errorf("unexpected age: want %d, got %d", 21, got.Age)
+	}
+
+	// commit
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtx := q.WithTx(tx)
+	if err := qtx.DeleteUser(ctx, users[0]); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := qtx.UpdateUserColumns(ctx, &User{ID: users[1].ID, Age: 31}, UserColumnAge); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	all, err := q.SelectAllUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Age != 31 {
+		t.Errorf("unexpected rows: %#v", all)
+	}
+}
+
+var errPrepare = errors.New("prepare failed")
+
+// failDriver is a database/sql driver that fails to prepare the statements.
+// The queries without the prepared statements return no rows.
+type failDriver struct{}
+
+func (failDriver) Open(name string) (driver.Conn, error) {
+	return failConn{}, nil
+}
+
+type failConn struct{}
+
+func (failConn) Prepare(query string) (driver.Stmt, error) { return nil, errPrepare }
+func (failConn) Close() error                              { return nil }
+func (failConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (failConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("queries-fail", failDriver{})
+}
+
+func TestQueries_PrepareError(t *testing.T) {
+	db, err := sql.Open("queries-fail", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	q := NewQueries(db)
+	defer q.Close()
+
+	// the error of preparing the statement isn't hidden by sql.ErrNoRows.
+	if _, err := q.SelectUser(context.Background(), &User{ID: 1}); !errors.Is(err, errPrepare) {
+		t.Errorf("want %v, got %v", errPrepare, err)
+	}
+	if _, err := q.SelectUserByIdxName(context.Background(), "alice"); !errors.Is(err, errPrepare) {
+		t.Errorf("want %v, got %v", errPrepare, err)
+	}
+}
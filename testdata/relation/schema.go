@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"database/sql"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type User struct {
+	ID   int64 `ddl:",auto"`
+	Name string
+}
+
+func (*User) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+type Post struct {
+	ID       int64 `ddl:",auto"`
+	UserID   int64
+	EditorID *int64 `ddl:",null"`
+	Title    string
+}
+
+func (*Post) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Post) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_user_id", "user_id"),
+		myddlmaker.NewIndex("idx_editor_id", "editor_id"),
+	}
+}
+
+func (*Post) ForeignKeys() []*myddlmaker.ForeignKey {
+	return []*myddlmaker.ForeignKey{
+		myddlmaker.NewForeignKey("fk_post_user", []string{"user_id"}, "user", []string{"id"}),
+		myddlmaker.NewForeignKey("fk_post_editor", []string{"editor_id"}, "user", []string{"id"}),
+	}
+}
+
+type Comment struct {
+	ID       int64 `ddl:",auto"`
+	PostID   int64
+	ParentID sql.NullInt64 `ddl:",null"`
+	Body     string
+}
+
+func (*Comment) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Comment) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_post_id", "post_id"),
+		myddlmaker.NewIndex("idx_parent_id", "parent_id"),
+	}
+}
+
+func (*Comment) ForeignKeys() []*myddlmaker.ForeignKey {
+	return []*myddlmaker.ForeignKey{
+		myddlmaker.NewForeignKey("fk_comment_post", []string{"post_id"}, "post", []string{"id"}),
+		myddlmaker.NewForeignKey("fk_comment_parent", []string{"parent_id"}, "comment", []string{"id"}),
+	}
+}
+
+type Shop struct {
+	Region string
+	Code   string
+	Name   string
+}
+
+func (*Shop) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("region", "code")
+}
+
+type Item struct {
+	ID         int64 `ddl:",auto"`
+	ShopRegion string
+	ShopCode   string
+}
+
+func (*Item) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Item) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_shop", "shop_region", "shop_code"),
+	}
+}
+
+func (*Item) ForeignKeys() []*myddlmaker.ForeignKey {
+	return []*myddlmaker.ForeignKey{
+		myddlmaker.NewForeignKey("fk_item_shop", []string{"shop_region", "shop_code"}, "shop", []string{"region", "code"}),
+	}
+}
+
+// Category is pluralized to "Categories" in the names of the loaders.
+type Category struct {
+	ID     int64 `ddl:",auto"`
+	UserID int64
+	Name   string
+}
+
+func (*Category) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Category) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_user_id", "user_id"),
+	}
+}
+
+func (*Category) ForeignKeys() []*myddlmaker.ForeignKey {
+	return []*myddlmaker.ForeignKey{
+		myddlmaker.NewForeignKey("fk_category_user", []string{"user_id"}, "user", []string{"id"}),
+	}
+}
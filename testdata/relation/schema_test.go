@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRelation(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	alice := &User{Name: "alice"}
+	bob := &User{Name: "bob"}
+	if err := InsertUser(ctx, db, alice, bob); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	posts := []*Post{
+		{UserID: alice.ID, EditorID: &bob.ID, Title: "hello"},
+		{UserID: alice.ID, Title: "world"},
+		{UserID: bob.ID, Title: "foo"},
+	}
+	if err := InsertPost(ctx, db, posts...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	// forward
+	user, err := SelectUserForPostByUserID(ctx, db, posts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != bob.ID {
+		t.Errorf("unexpected user: want %d, got %d", bob.ID, user.ID)
+	}
+	if _, err := SelectUserForPostByEditorID(ctx, db, posts[1]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+
+	// reverse
+	list, err := SelectPostsByUserID(ctx, db, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != posts[0].ID || list[1].ID != posts[1].ID {
+		t.Errorf("unexpected posts: %#v", list)
+	}
+
+	// batch
+	m, err := SelectPostsByEditorIDs(ctx, db, alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 || len(m[bob.ID]) != 1 || m[bob.ID][0].ID != posts[0].ID {
+		t.Errorf("unexpected posts: %#v", m)
+	}
+
+	// composite keys
+	shops := []*Shop{{Region: "jp", Code: "001", Name: "Tokyo"}, {Region: "us", Code: "001", Name: "New York"}}
+	if err := InsertShop(ctx, db, shops...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	items := []*Item{{ShopRegion: "jp", ShopCode: "001"}, {ShopRegion: "jp", ShopCode: "001"}, {ShopRegion: "us", ShopCode: "001"}}
+	if err := InsertItem(ctx, db, items...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	itemMap, err := SelectItemsByShops(ctx, db, shops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(itemMap[ShopPrimaryKey{Region: "jp", Code: "001"}]); n != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, n)
+	}
+	if n := len(itemMap[ShopPrimaryKey{Region: "us", Code: "001"}]); n != 1 {
+		t.Errorf("unexpected count: want %d, got %d", 1, n)
+	}
+}
+
+func TestRelation_Plural(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	user := &User{Name: "carol"}
+	if err := InsertUser(ctx, db, user); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	category := &Category{UserID: user.ID, Name: "news"}
+	if err := InsertCategory(ctx, db, category); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	categories, err := SelectCategoriesByUser(ctx, db, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0].ID != category.ID {
+		t.Errorf("unexpected categories: %#v", categories)
+	}
+
+	byUser, err := SelectCategoriesByUsers(ctx, db, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byUser[user.ID]) != 1 || byUser[user.ID][0].ID != category.ID {
+		t.Errorf("unexpected categories: %#v", byUser)
+	}
+}
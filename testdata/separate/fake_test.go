@@ -0,0 +1,27 @@
+//go:build fake
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shogo82148/myddlmaker/testdata/separate/model"
+)
+
+func TestFakeDB(t *testing.T) {
+	ctx := context.Background()
+	db := NewFakeDB()
+
+	user := &model.User{Name: "alice"}
+	if err := db.InsertUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.SelectUserByUniqName(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("unexpected id: want %d, got %d", user.ID, got.ID)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+
+	"github.com/shogo82148/myddlmaker"
+	"github.com/shogo82148/myddlmaker/testdata/separate/model"
+)
+
+func main() {
+	m, err := myddlmaker.New(&myddlmaker.Config{
+		PackageName:       "store",
+		StructPackagePath: "github.com/shogo82148/myddlmaker/testdata/separate/model",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	m.AddStructs(&model.User{}, &model.Post{})
+
+	if err := m.GenerateFile(); err != nil {
+		log.Fatal(err)
+	}
+	if err := m.GenerateGoFile(); err != nil {
+		log.Fatal(err)
+	}
+	if err := m.GenerateFakeFile(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/shogo82148/myddlmaker/testdata/separate/model"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestInsertAndSelect(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	user := &model.User{Name: "alice", CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := InsertUser(ctx, db, user); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	post := &model.Post{UserID: user.ID, Title: "hello"}
+	if err := InsertPost(ctx, db, post); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	got, err := SelectUserByUniqName(ctx, db, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("unexpected id: want %d, got %d", user.ID, got.ID)
+	}
+}
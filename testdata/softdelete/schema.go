@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"database/sql"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Post struct {
+	ID        int64 `ddl:",auto"`
+	AuthorID  int64
+	Slug      string
+	Title     string
+	DeletedAt sql.NullTime `ddl:",null,softdelete"`
+}
+
+func (*Post) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Post) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_author_id", "author_id"),
+	}
+}
+
+func (*Post) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		myddlmaker.NewUniqueIndex("idx_slug", "slug"),
+	}
+}
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSoftDeletePost(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	posts := []*Post{
+		{AuthorID: 1, Slug: "hello", Title: "Hello"},
+		{AuthorID: 1, Slug: "world", Title: "World"},
+		{AuthorID: 2, Slug: "foo", Title: "Foo"},
+	}
+	if err := InsertPost(ctx, db, posts...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	n, err := DeletePostRowsAffected(ctx, db, posts[0])
+	if err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("unexpected rows affected: want %d, got %d", 1, n)
+	}
+
+	// the deleted post is not changed again.
+	n, err = DeletePostRowsAffected(ctx, db, posts[0])
+	if err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("unexpected rows affected: want %d, got %d", 0, n)
+	}
+
+	if _, err := SelectPost(ctx, db, posts[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+	if _, err := SelectPostByIdxSlug(ctx, db, "hello"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+	got, err := SelectPostIncludingDeleted(ctx, db, posts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DeletedAt.Valid {
+		t.Errorf("deleted_at is not set")
+	}
+	if _, err := SelectPostByIdxSlugIncludingDeleted(ctx, db, "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := SelectAllPost(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(all))
+	}
+	all, err = SelectAllPostIncludingDeleted(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("unexpected count: want %d, got %d", 3, len(all))
+	}
+
+	list, err := SelectPostByPrimaryKeys(ctx, db, posts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(list))
+	}
+
+	var count int
+	for _, err := range IterAllPost(ctx, db) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, count)
+	}
+
+	page, _, err := ListPostByIdxAuthorID(ctx, db, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(page))
+	}
+
+	// the soft-deleted post is kept deleted by upserts.
+	if err := UpsertPost(ctx, db, &Post{ID: posts[0].ID, AuthorID: 1, Slug: "hello", Title: "Hello, again"}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	got, err = SelectPostIncludingDeleted(ctx, db, posts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DeletedAt.Valid {
+		t.Errorf("the soft-deleted post is restored")
+	}
+	if got.Title != "Hello, again" {
+		t.Errorf("unexpected title: want %q, got %q", "Hello, again", got.Title)
+	}
+	if _, err := SelectPost(ctx, db, posts[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+
+	// HardDeletePost deletes the soft-deleted rows too.
+	if err := HardDeletePost(ctx, db, posts...); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	all, err = SelectAllPostIncludingDeleted(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Errorf("unexpected count: want %d, got %d", 0, len(all))
+	}
+}
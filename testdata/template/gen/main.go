@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+	"text/template"
+
+	"github.com/shogo82148/myddlmaker"
+	schema "github.com/shogo82148/myddlmaker/testdata/template"
+)
+
+// the types of the fields need the imports of database/sql and time.
+const text = `// Code generated by gen/main.go; DO NOT EDIT.
+
+//go:build !{{.Tag}}
+
+package {{.PackageName}}
+
+{{imports "fmt"}}
+{{range .Tables}}
+// {{.GoName}}CacheKey returns the cache key of v.
+func {{.GoName}}CacheKey(v *{{goType .Type}}) string {
+	return fmt.Sprint("{{.Name}}"{{range pkColumns .}}, ":", v.{{.GoName}}{{end}})
+}
+
+// {{.GoName}}Patch has the fields of {{.GoName}} to be updated.
+type {{.GoName}}Patch struct {
+{{- range .Columns}}
+	{{.GoName}} *{{goType .Type}}
+{{- end}}
+}
+{{end}}`
+
+func main() {
+	m, err := myddlmaker.New(&myddlmaker.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	m.AddStructs(&schema.Event{})
+
+	if err := m.GenerateFile(); err != nil {
+		log.Fatal(err)
+	}
+	if err := m.GenerateGoFile(); err != nil {
+		log.Fatal(err)
+	}
+
+	tmpl := template.Must(template.New("cache_gen.go").Funcs(myddlmaker.TemplateFuncs()).Parse(text))
+	if err := m.GenerateTemplateFile("cache_gen.go", tmpl); err != nil {
+		log.Fatal(err)
+	}
+}
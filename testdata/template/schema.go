@@ -0,0 +1,19 @@
+package schema
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Event struct {
+	ID      int64 `ddl:",auto"`
+	Region  string
+	Note    sql.NullString `ddl:",null"`
+	StartAt time.Time
+}
+
+func (*Event) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id", "region")
+}
@@ -0,0 +1,26 @@
+package schema
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestEventCacheKey(t *testing.T) {
+	v := &Event{ID: 1, Region: "tokyo"}
+	if got, want := EventCacheKey(v), "event:1:tokyo"; got != want {
+		t.Errorf("unexpected key: want %q, got %q", want, got)
+	}
+}
+
+func TestEventPatch(t *testing.T) {
+	note := sql.NullString{String: "hello", Valid: true}
+	startAt := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	patch := EventPatch{Note: &note, StartAt: &startAt}
+	if patch.ID != nil || patch.Region != nil {
+		t.Errorf("unexpected patch: %#v", patch)
+	}
+	if !patch.StartAt.Equal(startAt) || patch.Note.String != "hello" {
+		t.Errorf("unexpected patch: %#v", patch)
+	}
+}
@@ -0,0 +1,18 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Note struct {
+	ID        int64 `ddl:",auto"`
+	Body      string
+	CreatedAt time.Time `ddl:",created"`
+	UpdatedAt time.Time `ddl:",updated,size=3"`
+}
+
+func (*Note) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
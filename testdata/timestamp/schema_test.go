@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTimestampNote(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = time.Now })
+
+	n := &Note{Body: "hello"}
+	if err := InsertNote(ctx, db, n); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	if !n.CreatedAt.Equal(created) {
+		t.Errorf("unexpected created_at: want %s, got %s", created, n.CreatedAt)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC); !n.UpdatedAt.Equal(want) {
+		t.Errorf("unexpected updated_at: want %s, got %s", want, n.UpdatedAt)
+	}
+
+	now = now.Add(time.Hour)
+	n.Body = "world"
+	n.CreatedAt = time.Time{}
+	if err := UpdateNote(ctx, db, n); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	updated := time.Date(2024, 1, 2, 4, 4, 5, 123000000, time.UTC)
+	if !n.UpdatedAt.Equal(updated) {
+		t.Errorf("unexpected updated_at: want %s, got %s", updated, n.UpdatedAt)
+	}
+
+	got, err := SelectNote(ctx, db, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("created_at is overwritten: want %s, got %s", created, got.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(updated) {
+		t.Errorf("unexpected updated_at: want %s, got %s", updated, got.UpdatedAt)
+	}
+
+	// the created column can't be updated.
+	if err := UpdateNoteColumns(ctx, db, got, NoteColumnCreatedAt); err == nil {
+		t.Error("want error, got nil")
+	}
+
+	now = now.Add(time.Hour)
+	got.Body = "foobar"
+	if err := UpdateNoteColumns(ctx, db, got, NoteColumnBody); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	updated = time.Date(2024, 1, 2, 5, 4, 5, 123000000, time.UTC)
+	if !got.UpdatedAt.Equal(updated) {
+		t.Errorf("unexpected updated_at: want %s, got %s", updated, got.UpdatedAt)
+	}
+
+	now = now.Add(time.Hour)
+	got.Body = "upserted"
+	if err := UpsertNote(ctx, db, got); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	got, err = SelectNote(ctx, db, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("created_at is overwritten: want %s, got %s", created, got.CreatedAt)
+	}
+	if want := time.Date(2024, 1, 2, 6, 4, 5, 123000000, time.UTC); !got.UpdatedAt.Equal(want) {
+		t.Errorf("unexpected updated_at: want %s, got %s", want, got.UpdatedAt)
+	}
+}
+
+func TestTimestampNote_Upsert(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	now := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = time.Now })
+
+	n := &Note{Body: "hello"}
+	if err := InsertNote(ctx, db, n); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	// upsert the existing row.
+	now = now.Add(time.Hour)
+	n.Body = "upserted"
+	if err := UpsertNote(ctx, db, n); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	got, err := SelectNote(ctx, db, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !n.CreatedAt.Equal(got.CreatedAt) {
+		t.Errorf("created_at is out of sync: want %s, got %s", got.CreatedAt, n.CreatedAt)
+	}
+	if !n.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Errorf("updated_at is out of sync: want %s, got %s", got.UpdatedAt, n.UpdatedAt)
+	}
+
+	// upsert a new row.
+	n2 := &Note{ID: n.ID + 1000, Body: "new"}
+	if err := UpsertNote(ctx, db, n2); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	if !n2.CreatedAt.Equal(now) {
+		t.Errorf("unexpected created_at: want %s, got %s", now, n2.CreatedAt)
+	}
+}
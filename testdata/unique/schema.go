@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type UserStatus int32
+
+type User struct {
+	ID     int32 `ddl:",auto"`
+	Email  string
+	Slug   sql.NullString `ddl:",null"`
+	Status UserStatus
+}
+
+func (*User) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*User) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		myddlmaker.NewUniqueIndex("uniq_email", "email"),
+		myddlmaker.NewUniqueIndex("uniq_slug", "slug"),
+	}
+}
+
+type Article struct {
+	ID          int32 `ddl:",auto"`
+	UserID      int32
+	PublishedAt time.Time
+	Type        *int32 `ddl:",null"`
+}
+
+func (*Article) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Article) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		// the name is generated from the naming convention.
+		myddlmaker.NewUniqueIndex("", "user_id", "published_at"),
+		myddlmaker.NewUniqueIndex("idx_user_id_type", "user_id", "type"),
+	}
+}
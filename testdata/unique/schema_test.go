@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSelectUserByUniqueIndexes(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	err := InsertUser(ctx, db, &User{
+		Email:  "alice@example.com",
+		Slug:   sql.NullString{String: "alice", Valid: true},
+		Status: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	u, err := SelectUserByUniqEmail(ctx, db, "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Slug.String != "alice" || u.Status != 1 {
+		t.Errorf("unexpected user: %#v", u)
+	}
+
+	u, err = SelectUserByUniqSlug(ctx, db, sql.NullString{String: "alice", Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "alice@example.com" {
+		t.Errorf("unexpected user: %#v", u)
+	}
+
+	if _, err := SelectUserByUniqEmail(ctx, db, "bob@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, but got %v", err)
+	}
+}
+
+func TestSelectArticleByUniqueIndexes(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	typ := int32(42)
+	err := InsertArticle(ctx, db, &Article{
+		UserID:      1,
+		PublishedAt: now,
+		Type:        &typ,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	a, err := SelectArticleByUniqArticleUserIDPublishedAt(ctx, db, 1, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Type == nil || *a.Type != 42 {
+		t.Errorf("unexpected article: %#v", a)
+	}
+
+	a, err = SelectArticleByIdxUserIDType(ctx, db, 1, &typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.PublishedAt.Equal(now) {
+		t.Errorf("unexpected article: %#v", a)
+	}
+
+	if _, err := SelectArticleByUniqArticleUserIDPublishedAt(ctx, db, 2, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("want sql.ErrNoRows, but got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUpsertUser(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	users := []*User{}
+	for i := 0; i < 100; i++ {
+		users = append(users, &User{ID: int32(i + 1), Name: "user", Age: 20})
+	}
+	if err := UpsertUser(ctx, db, users...); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+
+	// update all columns.
+	if err := UpsertUser(ctx, db, &User{ID: 1, Name: "alice", Age: 21}, &User{ID: 101, Name: "bob", Age: 22}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	u, err := SelectUser(ctx, db, &User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "alice" || u.Age != 21 {
+		t.Errorf("unexpected user: %#v", u)
+	}
+
+	// update only the name column.
+	if err := UpsertUserColumns(ctx, db, []UserColumn{UserColumnName}, &User{ID: 2, Name: "charlie", Age: 30}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	u, err = SelectUser(ctx, db, &User{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "charlie" || u.Age != 20 {
+		t.Errorf("unexpected user: %#v", u)
+	}
+
+	// no column is updated.
+	if err := UpsertUserColumns(ctx, db, nil, &User{ID: 3, Name: "dave", Age: 40}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	u, err = SelectUser(ctx, db, &User{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "user" || u.Age != 20 {
+		t.Errorf("unexpected user: %#v", u)
+	}
+
+	all, err := SelectAllUser(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 101 {
+		t.Errorf("unexpected count: want %d, got %d", 101, len(all))
+	}
+}
+
+func TestUpsertUserColumns_UnknownColumn(t *testing.T) {
+	// the column names are validated before executing the query.
+	err := UpsertUserColumns(context.Background(), nil, []UserColumn{UserColumnName, "unknown"}, &User{ID: 1})
+	if err == nil {
+		t.Error("want some error, got nil")
+	}
+}
+
+func TestUpsertTag(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// the table has only the primary key.
+	if err := UpsertTag(ctx, db, &Tag{Name: "go"}, &Tag{Name: "mysql"}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	if err := UpsertTag(ctx, db, &Tag{Name: "go"}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	all, err := SelectAllTag(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(all))
+	}
+}
+
+func TestUpsertItem(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	items := []*Item{{Name: "apple"}, {Name: "banana"}}
+	if err := InsertItem(ctx, db, items...); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	// the row that has the same auto increment ID is updated.
+	if err := UpsertItem(ctx, db, &Item{ID: items[0].ID, Name: "cherry"}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	all, err := SelectAllItem(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("unexpected count: want %d, got %d", 2, len(all))
+	}
+	item, err := SelectItem(ctx, db, &Item{ID: items[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Name != "cherry" {
+		t.Errorf("unexpected item: %#v", item)
+	}
+
+	// the zero ID is generated by MySQL.
+	if err := UpsertItem(ctx, db, &Item{Name: "durian"}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	all, err = SelectAllItem(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("unexpected count: want %d, got %d", 3, len(all))
+	}
+}
@@ -0,0 +1,16 @@
+package schema
+
+import (
+	"github.com/shogo82148/myddlmaker"
+)
+
+type Account struct {
+	ID      int32 `ddl:",auto"`
+	Name    string
+	Balance int64
+	Version int32 `ddl:",version"`
+}
+
+func (*Account) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	name := os.Getenv("MYSQL_TEST_DB")
+	if name == "" {
+		t.Skip("MYSQL_TEST_DB is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	cfg.DBName = name
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUpdateAccount(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	a := &Account{Name: "alice", Balance: 100}
+	if err := InsertAccount(ctx, db, a); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	a1, err := SelectAccount(ctx, db, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := SelectAccount(ctx, db, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1.Balance += 10
+	if err := UpdateAccount(ctx, db, a1); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if a1.Version != 1 {
+		t.Errorf("unexpected version: want %d, got %d", 1, a1.Version)
+	}
+
+	// a2 is stale.
+	a2.Balance += 20
+	err = UpdateAccount(ctx, db, a2)
+	var stale *ErrStaleObject
+	if !errors.As(err, &stale) {
+		t.Fatalf("want ErrStaleObject, got %v", err)
+	}
+	if stale.Table != "account" || len(stale.PrimaryKey) != 1 || stale.PrimaryKey[0] != a.ID {
+		t.Errorf("unexpected error: %#v", stale)
+	}
+	if a2.Version != 0 {
+		t.Errorf("unexpected version: want %d, got %d", 0, a2.Version)
+	}
+
+	// UpdateAccountColumns also checks the version.
+	if err := UpdateAccountColumns(ctx, db, a2, AccountColumnBalance); !errors.As(err, &stale) {
+		t.Fatalf("want ErrStaleObject, got %v", err)
+	}
+	a1.Name = "ALICE"
+	if err := UpdateAccountColumns(ctx, db, a1, AccountColumnName); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if a1.Version != 2 {
+		t.Errorf("unexpected version: want %d, got %d", 2, a1.Version)
+	}
+
+	// the version column can't be updated directly.
+	if err := UpdateAccountColumns(ctx, db, a1, AccountColumnVersion); err == nil {
+		t.Error("want some error, got nil")
+	}
+
+	got, err := SelectAccount(ctx, db, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "ALICE" || got.Balance != 110 || got.Version != 2 {
+		t.Errorf("unexpected account: %#v", got)
+	}
+
+	// the deleted row is also stale.
+	if err := DeleteAccount(ctx, db, a1); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := UpdateAccount(ctx, db, a1); !errors.As(err, &stale) {
+		t.Fatalf("want ErrStaleObject, got %v", err)
+	}
+}
+
+func TestUpsertAccount(t *testing.T) {
+	db := openDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	a := &Account{Name: "bob", Balance: 100}
+	if err := InsertAccount(ctx, db, a); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	// the version is incremented.
+	if err := UpsertAccount(ctx, db, &Account{ID: a.ID, Name: "bob", Balance: 200}); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	got, err := SelectAccount(ctx, db, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 1 {
+		t.Errorf("unexpected version: want %d, got %d", 1, got.Version)
+	}
+}
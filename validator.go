@@ -3,6 +3,11 @@ package myddlmaker
 import (
 	"fmt"
 	"log"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
 )
 
 type validationError struct {
@@ -15,6 +20,8 @@ func (e *validationError) Error() string {
 
 type validator struct {
 	SkipValidationFKIndex bool
+	Naming                *NamingConfig
+	DB                    *DBConfig
 
 	tables []*table
 	errs   []string
@@ -40,6 +47,10 @@ func (v *validator) Validate() error {
 	for _, table := range v.tables {
 		v.validateIndex(table)
 		v.validateIndexName(table)
+		v.validateNaming(table)
+		v.validateVersion(table)
+		v.validateSoftDelete(table)
+		v.validateTimestamps(table)
 	}
 	v.validateConstraints()
 	v.validateForeignKeys()
@@ -60,6 +71,11 @@ func (v *validator) SaveErrorf(format string, args ...any) {
 	log.Printf(format, args...)
 }
 
+// SaveWarningf logs the message, but it is not an error.
+func (v *validator) SaveWarningf(format string, args ...any) {
+	log.Printf("warning: "+format, args...)
+}
+
 func (v *validator) Err() error {
 	if len(v.errs) == 0 {
 		return nil
@@ -166,6 +182,167 @@ func (v *validator) validateIndexName(table *table) {
 	}
 }
 
+func (v *validator) validateNaming(table *table) {
+	naming := v.Naming
+	if naming == nil {
+		naming = new(NamingConfig)
+	}
+
+	v.validateName(fmt.Sprintf("table %q", table.name), table.name, naming.TablePattern)
+	for _, col := range table.columns {
+		v.validateName(fmt.Sprintf("table %q, column %q", table.name, col.name), col.name, naming.ColumnPattern)
+	}
+	for _, idx := range table.indexes {
+		v.validateName(fmt.Sprintf("table %q, index %q", table.name, idx.name), idx.name, naming.IndexPattern)
+	}
+	for _, idx := range table.uniqueIndexes {
+		v.validateName(fmt.Sprintf("table %q, unique index %q", table.name, idx.name), idx.name, naming.IndexPattern)
+	}
+	for _, idx := range table.fullTextIndexes {
+		v.validateName(fmt.Sprintf("table %q, full-text index %q", table.name, idx.name), idx.name, naming.IndexPattern)
+	}
+	for _, idx := range table.spatialIndexes {
+		v.validateName(fmt.Sprintf("table %q, spatial index %q", table.name, idx.name), idx.name, naming.IndexPattern)
+	}
+	for _, fk := range table.foreignKeys {
+		v.validateName(fmt.Sprintf("table %q, foreign key %q", table.name, fk.name), fk.name, naming.ConstraintPattern)
+	}
+}
+
+func (v *validator) validateName(prefix, name string, pattern *regexp.Regexp) {
+	if utf8.RuneCountInString(name) > maxIdentifierLength {
+		v.SaveErrorf("%s: name is too long (maximum is %d characters)", prefix, maxIdentifierLength)
+	}
+	if pattern != nil && !pattern.MatchString(name) {
+		v.SaveErrorf("%s: name does not match the pattern %q", prefix, pattern.String())
+	}
+
+	if v.Naming == nil {
+		return
+	}
+	if v.Naming.SnakeCase && !snakeCasePattern.MatchString(name) {
+		v.SaveErrorf("%s: name is not snake_case", prefix)
+	}
+	for _, word := range v.Naming.ReservedWords {
+		if strings.EqualFold(name, word) {
+			v.SaveErrorf("%s: name is a reserved word", prefix)
+			return
+		}
+	}
+	if IsReservedWord(name) {
+		switch v.Naming.ReservedWordPolicy {
+		case ReservedWordWarn, ReservedWordRename:
+			v.SaveWarningf("%s: name is a reserved word of MySQL", prefix)
+		case ReservedWordError:
+			v.SaveErrorf("%s: name is a reserved word of MySQL", prefix)
+		}
+	}
+}
+
+func (v *validator) validateVersion(table *table) {
+	var found bool
+	for _, col := range table.columns {
+		if !col.version {
+			continue
+		}
+		if found {
+			v.SaveErrorf("table %q, column %q: duplicated version column", table.name, col.name)
+			continue
+		}
+		found = true
+
+		if col.fieldType.Kind() == reflect.Pointer {
+			v.SaveErrorf("table %q, column %q: the field of version column can't be a pointer", table.name, col.name)
+		} else if !isIntegerKind(col.rawType.Kind()) || !isIntegerType(col.typ) {
+			v.SaveErrorf("table %q, column %q: version column must be an integer: %s", table.name, col.name, v.columnDefinition(col))
+		}
+		if col.null {
+			v.SaveErrorf("table %q, column %q: version column must be NOT NULL", table.name, col.name)
+		}
+		if col.autoIncr {
+			v.SaveErrorf("table %q, column %q: version column can't be AUTO_INCREMENT", table.name, col.name)
+		}
+		if table.primaryKey != nil && slices.Contains(table.primaryKey.columns, col.name) {
+			v.SaveErrorf("table %q, column %q: version column can't be a part of the primary key", table.name, col.name)
+		}
+	}
+}
+
+func (v *validator) validateSoftDelete(table *table) {
+	var found bool
+	for _, col := range table.columns {
+		if !col.softDelete {
+			continue
+		}
+		if found {
+			v.SaveErrorf("table %q, column %q: duplicated soft delete column", table.name, col.name)
+			continue
+		}
+		found = true
+
+		if (col.rawType != timeType && col.rawType != nullTimeType) || !isTimeType(col.typ) {
+			v.SaveErrorf("table %q, column %q: soft delete column must be a time: %s", table.name, col.name, v.columnDefinition(col))
+		}
+		if !col.null {
+			v.SaveErrorf("table %q, column %q: soft delete column must be NULL", table.name, col.name)
+		}
+		if table.primaryKey != nil && slices.Contains(table.primaryKey.columns, col.name) {
+			v.SaveErrorf("table %q, column %q: soft delete column can't be a part of the primary key", table.name, col.name)
+		}
+	}
+}
+
+func (v *validator) validateTimestamps(table *table) {
+	var created, updated bool
+	for _, col := range table.columns {
+		if !col.created && !col.updated {
+			continue
+		}
+		if col.created && col.updated {
+			v.SaveErrorf("table %q, column %q: column can't be both created and updated", table.name, col.name)
+		}
+		if col.created {
+			if created {
+				v.SaveErrorf("table %q, column %q: duplicated created column", table.name, col.name)
+			}
+			created = true
+		}
+		if col.updated {
+			if updated {
+				v.SaveErrorf("table %q, column %q: duplicated updated column", table.name, col.name)
+			}
+			updated = true
+		}
+
+		if col.fieldType != timeType {
+			v.SaveErrorf("table %q, column %q: the field of timestamp column must be time.Time", table.name, col.name)
+		} else if !isTimeType(col.typ) {
+			v.SaveErrorf("table %q, column %q: timestamp column must be DATETIME or TIMESTAMP: %s", table.name, col.name, v.columnDefinition(col))
+		}
+		if table.primaryKey != nil && slices.Contains(table.primaryKey.columns, col.name) {
+			v.SaveErrorf("table %q, column %q: timestamp column can't be a part of the primary key", table.name, col.name)
+		}
+	}
+}
+
+// isTimeType reports whether typ is a date and time type of MySQL that has the time part.
+func isTimeType(typ string) bool {
+	switch strings.ToUpper(typ) {
+	case "DATETIME", "TIMESTAMP":
+		return true
+	}
+	return false
+}
+
+// isIntegerType reports whether typ is an integer type of MySQL.
+func isIntegerType(typ string) bool {
+	switch strings.ToUpper(typ) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		return true
+	}
+	return false
+}
+
 func (v *validator) validateConstraints() {
 	seen := map[string]struct{}{}
 
@@ -201,7 +378,7 @@ func (v *validator) validateFKColumns(table *table, fk *ForeignKey) {
 	}
 
 	if !v.SkipValidationFKIndex {
-		if passed && !v.hasIndex(table, fk.columns) {
+		if passed && !table.hasIndex(fk.columns) {
 			v.SaveErrorf("table %q, foreign key %q: index required on table %q", table.name, fk.name, table.name)
 		}
 	}
@@ -223,6 +400,10 @@ func (v *validator) validateFKRef(table *table, fk *ForeignKey) {
 			continue
 		}
 
+		if refcol.null {
+			v.SaveErrorf("table %q, foreign key %q: referenced column %q.%q must be NOT NULL: %s", table.name, fk.name, ref.name, col, v.columnDefinition(refcol))
+		}
+
 		// type check
 		mycol, ok := v.columnMap[[2]string{table.name, fk.columns[i]}]
 		if !ok {
@@ -231,51 +412,99 @@ func (v *validator) validateFKRef(table *table, fk *ForeignKey) {
 			continue
 		}
 		if refcol.typ != mycol.typ || refcol.unsigned != mycol.unsigned {
-			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q type mismatch", table.name, fk.name, mycol.name, ref.name, col)
+			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q type mismatch: %s vs %s", table.name, fk.name, mycol.name, ref.name, col, v.columnDefinition(mycol), v.columnDefinition(refcol))
+		} else if isStringType(mycol.typ) && refcol.size != mycol.size {
+			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q size mismatch: %s vs %s", table.name, fk.name, mycol.name, ref.name, col, v.columnDefinition(mycol), v.columnDefinition(refcol))
+		}
+		if v.charset(refcol) != v.charset(mycol) {
+			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q character set mismatch: %s vs %s", table.name, fk.name, mycol.name, ref.name, col, v.columnDefinition(mycol), v.columnDefinition(refcol))
+		}
+		if v.collate(refcol) != v.collate(mycol) {
+			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q collate mismatch: %s vs %s", table.name, fk.name, mycol.name, ref.name, col, v.columnDefinition(mycol), v.columnDefinition(refcol))
 		}
-		if refcol.charset != mycol.charset {
-			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q character set mismatch", table.name, fk.name, mycol.name, ref.name, col)
+
+		// SET NULL requires nullable columns.
+		if fk.onDelete == ForeignKeyOptionSetNull && !mycol.null {
+			v.SaveErrorf("table %q, foreign key %q: ON DELETE SET NULL requires nullable column %q: %s", table.name, fk.name, mycol.name, v.columnDefinition(mycol))
 		}
-		if refcol.collate != mycol.collate {
-			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q collate mismatch", table.name, fk.name, mycol.name, ref.name, col)
+		if fk.onUpdate == ForeignKeyOptionSetNull && !mycol.null {
+			v.SaveErrorf("table %q, foreign key %q: ON UPDATE SET NULL requires nullable column %q: %s", table.name, fk.name, mycol.name, v.columnDefinition(mycol))
 		}
 	}
 
+	if passed && !ref.hasUniqueKey(fk.references) {
+		// https://dev.mysql.com/doc/refman/8.4/en/create-table-foreign-keys.html
+		// MySQL 8.4 rejects the foreign keys that reference non-unique keys by default.
+		v.SaveErrorf("table %q, foreign key %q: referenced columns %q of table %q must be the primary key or a unique index", table.name, fk.name, fk.references, ref.name)
+		return
+	}
+
 	if !v.SkipValidationFKIndex {
-		if passed && !v.hasIndex(ref, fk.references) {
+		if passed && !ref.hasIndex(fk.references) {
 			v.SaveErrorf("table %q, foreign key %q: index required on table %q", table.name, fk.name, ref.name)
 		}
 	}
 }
 
-func (v *validator) hasIndex(table *table, cols []string) bool {
-	if v.hasPrefix(table.primaryKey.columns, cols) {
-		return true
+// charset returns the effective character set of col.
+func (v *validator) charset(col *column) string {
+	if col.charset != "" || v.DB == nil {
+		return col.charset
 	}
+	return v.DB.Charset
+}
 
-	for _, idx := range table.indexes {
-		if v.hasPrefix(idx.columns, cols) {
-			return true
-		}
+// collate returns the effective collation of col.
+func (v *validator) collate(col *column) string {
+	if col.collate != "" || col.charset != "" || v.DB == nil {
+		// if the character set is specified, the default collation of it is used.
+		return col.collate
 	}
+	return v.DB.Collate
+}
 
-	for _, idx := range table.uniqueIndexes {
-		if v.hasPrefix(idx.columns, cols) {
-			return true
+// columnDefinition returns the definition of col for error messages.
+func (v *validator) columnDefinition(col *column) string {
+	var buf strings.Builder
+	buf.WriteString(col.typ)
+	if col.size != 0 {
+		fmt.Fprintf(&buf, "(%d)", col.size)
+	}
+	if isCharType(col.typ) {
+		if charset := v.charset(col); charset != "" {
+			buf.WriteString(" CHARACTER SET ")
+			buf.WriteString(charset)
+		}
+		if collate := v.collate(col); collate != "" {
+			buf.WriteString(" COLLATE ")
+			buf.WriteString(collate)
 		}
 	}
+	if col.unsigned {
+		buf.WriteString(" UNSIGNED")
+	}
+	if col.null {
+		buf.WriteString(" NULL")
+	} else {
+		buf.WriteString(" NOT NULL")
+	}
+	return buf.String()
+}
 
+// isStringType reports whether typ is a string type that has its length.
+func isStringType(typ string) bool {
+	switch strings.ToUpper(typ) {
+	case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+		return true
+	}
 	return false
 }
 
-func (v *validator) hasPrefix(s []string, prefix []string) bool {
-	if len(s) < len(prefix) {
-		return false
-	}
-	for i := range prefix {
-		if s[i] != prefix[i] {
-			return false
-		}
+// isCharType reports whether typ is a nonbinary string type that has its character set.
+func isCharType(typ string) bool {
+	switch strings.ToUpper(typ) {
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET":
+		return true
 	}
-	return true
+	return false
 }